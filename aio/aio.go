@@ -0,0 +1,302 @@
+// Package aio provides asynchronous file I/O as promises. On Linux requests
+// are submitted in batches through io_uring; everywhere else, or when
+// io_uring is unavailable, they are served by a fixed pool of goroutines.
+package aio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/barklan/async"
+)
+
+// ErrClosed is returned by promises created after the Engine was closed.
+var ErrClosed = errors.New("aio: engine closed")
+
+// Config configures an Engine.
+type Config struct {
+	// Entries is the size of the io_uring submission queue, and so the
+	// maximum number of requests submitted in a single batch. Defaults to 256.
+	Entries uint32
+
+	// Workers is the number of goroutines used when io_uring is unavailable.
+	// Defaults to 4 * runtime.NumCPU().
+	Workers int
+
+	// DisableIOURing forces the goroutine pool even when io_uring is
+	// available.
+	DisableIOURing bool
+}
+
+type op uint8
+
+const (
+	opRead op = iota
+	opWrite
+	opFsync
+)
+
+type request struct {
+	op     op
+	file   *os.File
+	buf    []byte
+	off    int64
+	n      int // bytes transferred so far
+	settle func(n int, err error)
+}
+
+// do performs the request synchronously.
+func (r *request) do() {
+	switch r.op {
+	case opRead:
+		r.settle(r.file.ReadAt(r.buf, r.off))
+	case opWrite:
+		r.settle(r.file.WriteAt(r.buf, r.off))
+	case opFsync:
+		r.settle(0, r.file.Sync())
+	}
+}
+
+// complete records the raw result of a system call, and reports whether the
+// rest of the buffer must be submitted again. Like os.File.ReadAt and
+// WriteAt, a request only settles once the buffer is done, an error occurs
+// or a read reaches the end of the file.
+func (r *request) complete(n int, errno error) (again bool) {
+	if errno != nil {
+		name := [...]string{opRead: "read", opWrite: "write", opFsync: "sync"}[r.op]
+		r.settle(r.n, &os.PathError{Op: name, Path: r.file.Name(), Err: errno})
+		return false
+	}
+	if r.op == opFsync {
+		r.settle(0, nil)
+		return false
+	}
+	r.n += n
+	switch {
+	case r.n == len(r.buf):
+		r.settle(r.n, nil)
+	case n > 0:
+		return true
+	case r.op == opRead:
+		r.settle(r.n, io.EOF)
+	default:
+		r.settle(r.n, io.ErrShortWrite)
+	}
+	return false
+}
+
+type backend interface {
+	submit(*request)
+	close()
+}
+
+// Engine submits file I/O requests and delivers their results as promises.
+// Submitting never blocks the caller: requests are queued until io_uring or a
+// worker can take them, however many are outstanding.
+type Engine struct {
+	mu     sync.RWMutex
+	closed bool
+	b      backend
+	uring  bool
+}
+
+// New creates an Engine, using io_uring when it is available.
+func New(cfg Config) *Engine {
+	if cfg.Entries == 0 {
+		cfg.Entries = 256
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4 * runtime.NumCPU()
+	}
+	if !cfg.DisableIOURing {
+		if b, err := newURing(cfg.Entries); err == nil {
+			return &Engine{b: b, uring: true}
+		}
+	}
+	return &Engine{b: newPool(cfg.Workers)}
+}
+
+// IOURing reports whether the engine submits requests through io_uring.
+func (e *Engine) IOURing() bool {
+	return e.uring
+}
+
+// Close waits for all outstanding requests to complete and releases the
+// engine's resources. Requests made after Close are rejected with ErrClosed.
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	e.b.close()
+	return nil
+}
+
+func (e *Engine) submit(r *request) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return ErrClosed
+	}
+	e.b.submit(r)
+	return nil
+}
+
+// ReadAt reads len(b) bytes from f starting at offset off. Like
+// io.ReaderAt, the promise is rejected with io.EOF alongside a short count
+// when the end of the file is reached. b MUST NOT be touched until the
+// promise settles.
+func (e *Engine) ReadAt(f *os.File, b []byte, off int64) async.Promise[int] {
+	p := newPromise[int]()
+	r := &request{op: opRead, file: f, buf: b, off: off, settle: p.settle}
+	if err := e.submit(r); err != nil {
+		return async.Reject[int](err)
+	}
+	return p
+}
+
+// WriteAt writes b to f starting at offset off. b MUST NOT be touched until
+// the promise settles.
+func (e *Engine) WriteAt(f *os.File, b []byte, off int64) async.Promise[int] {
+	p := newPromise[int]()
+	r := &request{op: opWrite, file: f, buf: b, off: off, settle: p.settle}
+	if err := e.submit(r); err != nil {
+		return async.Reject[int](err)
+	}
+	return p
+}
+
+// Fsync commits the contents of f to stable storage.
+func (e *Engine) Fsync(f *os.File) async.Promise[struct{}] {
+	p := newPromise[struct{}]()
+	r := &request{op: opFsync, file: f, settle: func(_ int, err error) {
+		p.settle(struct{}{}, err)
+	}}
+	if err := e.submit(r); err != nil {
+		return async.Reject[struct{}](err)
+	}
+	return p
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultEngine *Engine
+)
+
+// Default returns the engine used by the package level functions. It is
+// created with the zero Config on first use.
+func Default() *Engine {
+	defaultOnce.Do(func() {
+		defaultEngine = New(Config{})
+	})
+	return defaultEngine
+}
+
+// ReadAt calls ReadAt on the Default engine.
+func ReadAt(f *os.File, b []byte, off int64) async.Promise[int] {
+	return Default().ReadAt(f, b, off)
+}
+
+// WriteAt calls WriteAt on the Default engine.
+func WriteAt(f *os.File, b []byte, off int64) async.Promise[int] {
+	return Default().WriteAt(f, b, off)
+}
+
+// Fsync calls Fsync on the Default engine.
+func Fsync(f *os.File) async.Promise[struct{}] {
+	return Default().Fsync(f)
+}
+
+type promise[T any] struct {
+	done chan struct{}
+	v    T
+	err  error
+}
+
+func newPromise[T any]() *promise[T] {
+	return &promise[T]{done: make(chan struct{})}
+}
+
+func (p *promise[T]) settle(v T, err error) {
+	p.v, p.err = v, err
+	close(p.done)
+}
+
+func (p *promise[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zerov T
+		return zerov, ctx.Err()
+	case <-p.done:
+		return p.v, p.err
+	}
+}
+
+func (p *promise[T]) Settled() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// pool serves requests from an unbounded queue, so that submitting never
+// waits for a free worker.
+type pool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []*request
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newPool(workers int) *pool {
+	p := &pool{}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *pool) work() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		r := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		r.do()
+	}
+}
+
+func (p *pool) submit(r *request) {
+	p.mu.Lock()
+	p.queue = append(p.queue, r)
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+func (p *pool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	p.wg.Wait()
+}
@@ -0,0 +1,138 @@
+package aio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/barklan/async"
+)
+
+func TestEngine(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "default", cfg: Config{Entries: 8}},
+		{name: "pool", cfg: Config{DisableIOURing: true, Workers: 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(tc.cfg)
+			defer e.Close()
+			t.Logf("io_uring: %v", e.IOURing())
+			testEngine(t, e)
+		})
+	}
+}
+
+func testEngine(t *testing.T, e *Engine) {
+	ctx := context.Background()
+	f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	const chunk = 16
+	writes := make([]async.Promise[int], 32)
+	for i := range writes {
+		writes[i] = e.WriteAt(f, bytes.Repeat([]byte{byte('a' + i%26)}, chunk), int64(i*chunk))
+	}
+	for _, w := range writes {
+		n, err := w.Await(ctx)
+		if err != nil || n != chunk {
+			t.Fatalf("write: %d, %v", n, err)
+		}
+	}
+	if _, err := e.Fsync(f).Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, chunk)
+	n, err := e.ReadAt(f, buf, 3*chunk).Await(ctx)
+	if err != nil || n != chunk || !bytes.Equal(buf, bytes.Repeat([]byte{'d'}, chunk)) {
+		t.Fatalf("read: %d, %v, %q", n, err, buf)
+	}
+
+	n, err = e.ReadAt(f, make([]byte, 2*chunk), 31*chunk).Await(ctx)
+	if n != chunk || err != io.EOF {
+		t.Fatalf("read past end: %d, %v", n, err)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.ReadAt(f, buf, 0).Await(ctx); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestEngineBadFile(t *testing.T) {
+	f, err := os.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	e := New(Config{})
+	defer e.Close()
+	_, err = e.ReadAt(f, make([]byte, 1), 0).Await(context.Background())
+	var perr *os.PathError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *os.PathError, got %v", err)
+	}
+}
+
+func TestRequestPartial(t *testing.T) {
+	var n int
+	var err error
+	settled := false
+	r := &request{op: opRead, buf: make([]byte, 8), settle: func(rn int, rerr error) {
+		n, err, settled = rn, rerr, true
+	}}
+	if !r.complete(3, nil) || !r.complete(2, nil) || settled {
+		t.Fatal("expected the rest of a partial read to be resubmitted")
+	}
+	if r.complete(0, nil) || !settled || n != 5 || err != io.EOF {
+		t.Fatalf("read to end: %v, %d, %v", settled, n, err)
+	}
+
+	settled = false
+	r = &request{op: opWrite, buf: make([]byte, 8), settle: func(rn int, rerr error) {
+		n, err, settled = rn, rerr, true
+	}}
+	if !r.complete(6, nil) || r.complete(2, nil) || !settled || n != 8 || err != nil {
+		t.Fatalf("write: %v, %d, %v", settled, n, err)
+	}
+}
+
+func TestEngineManyRequests(t *testing.T) {
+	for _, cfg := range []Config{{Entries: 4}, {DisableIOURing: true, Workers: 1}} {
+		e := New(cfg)
+		f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte("abcd")); err != nil {
+			t.Fatal(err)
+		}
+		// far more requests than there are slots or workers; submitting
+		// queues them instead of waiting
+		reads := make([]async.Promise[int], 2000)
+		for i := range reads {
+			reads[i] = e.ReadAt(f, make([]byte, 2), int64(i%3))
+		}
+		for _, r := range reads {
+			if n, err := r.Await(context.Background()); err != nil || n != 2 {
+				t.Fatalf("read: %d, %v", n, err)
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,416 @@
+package aio
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	sysIOURingSetup = 425
+	sysIOURingEnter = 426
+
+	offSQRing = 0
+	offCQRing = 0x8000000
+	offSQEs   = 0x10000000
+
+	enterGetEvents = 1 << 0
+
+	uringNop    = 0
+	uringReadv  = 1
+	uringWritev = 2
+	uringFsync  = 3
+)
+
+// The following types mirror the kernel's io_uring ABI.
+
+type sqRingOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	userAddr                                                        uint64
+}
+
+type cqRingOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	userAddr                                                        uint64
+}
+
+type uringParams struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  sqRingOffsets
+	cqOff                                                                  cqRingOffsets
+}
+
+type uringSQE struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	opFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	_           uint64
+}
+
+type uringCQE struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// closeTag is the user data of the no-op submitted when the ring shuts down.
+// Real requests are numbered from 1.
+const closeTag = 0
+
+type uringOp struct {
+	r   *request
+	iov syscall.Iovec
+}
+
+type uring struct {
+	fd int
+
+	sqMem, cqMem, sqeMem []byte
+
+	sqTail  *uint32
+	sqMask  uint32
+	sqSize  uint32
+	sqArray []uint32
+	sqes    []uringSQE
+
+	cqHead *uint32
+	cqTail *uint32
+	cqMask uint32
+	cqes   []uringCQE
+
+	slots      chan struct{} // bounds in-flight requests to the completion queue size
+	wake       chan struct{} // signals requests waiting in queue
+	active     sync.WaitGroup
+	submitDone chan struct{}
+	reapDone   chan struct{}
+
+	mu       sync.Mutex
+	inflight map[uint64]*uringOp
+	next     uint64
+	queue    []*request // new requests and the rest of partial transfers
+	closing  bool
+	err      error         // set once the ring is broken
+	broken   chan struct{} // closed along with err
+}
+
+func newURing(entries uint32) (backend, error) {
+	var p uringParams
+	fd, _, errno := syscall.Syscall(sysIOURingSetup, uintptr(entries), uintptr(unsafe.Pointer(&p)), 0)
+	if errno != 0 {
+		return nil, os.NewSyscallError("io_uring_setup", errno)
+	}
+	u := &uring{
+		fd:         int(fd),
+		slots:      make(chan struct{}, p.cqEntries),
+		wake:       make(chan struct{}, 1),
+		submitDone: make(chan struct{}),
+		reapDone:   make(chan struct{}),
+		inflight:   map[uint64]*uringOp{},
+		broken:     make(chan struct{}),
+	}
+	if err := u.mmap(&p); err != nil {
+		u.release()
+		return nil, err
+	}
+	go u.submitLoop()
+	go u.reapLoop()
+	return u, nil
+}
+
+func (u *uring) mmap(p *uringParams) error {
+	const prot = syscall.PROT_READ | syscall.PROT_WRITE
+	const flags = syscall.MAP_SHARED | syscall.MAP_POPULATE
+	var err error
+	u.sqMem, err = syscall.Mmap(u.fd, offSQRing, int(p.sqOff.array+p.sqEntries*4), prot, flags)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	u.cqMem, err = syscall.Mmap(u.fd, offCQRing, int(p.cqOff.cqes+p.cqEntries*uint32(unsafe.Sizeof(uringCQE{}))), prot, flags)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	u.sqeMem, err = syscall.Mmap(u.fd, offSQEs, int(p.sqEntries*uint32(unsafe.Sizeof(uringSQE{}))), prot, flags)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	u.sqTail = (*uint32)(unsafe.Pointer(&u.sqMem[p.sqOff.tail]))
+	u.sqMask = *(*uint32)(unsafe.Pointer(&u.sqMem[p.sqOff.ringMask]))
+	u.sqSize = p.sqEntries
+	u.sqArray = unsafe.Slice((*uint32)(unsafe.Pointer(&u.sqMem[p.sqOff.array])), p.sqEntries)
+	u.sqes = unsafe.Slice((*uringSQE)(unsafe.Pointer(&u.sqeMem[0])), p.sqEntries)
+	u.cqHead = (*uint32)(unsafe.Pointer(&u.cqMem[p.cqOff.head]))
+	u.cqTail = (*uint32)(unsafe.Pointer(&u.cqMem[p.cqOff.tail]))
+	u.cqMask = *(*uint32)(unsafe.Pointer(&u.cqMem[p.cqOff.ringMask]))
+	u.cqes = unsafe.Slice((*uringCQE)(unsafe.Pointer(&u.cqMem[p.cqOff.cqes])), p.cqEntries)
+	return nil
+}
+
+func (u *uring) release() {
+	for _, m := range [][]byte{u.sqMem, u.cqMem, u.sqeMem} {
+		if m != nil {
+			_ = syscall.Munmap(m)
+		}
+	}
+	_ = syscall.Close(u.fd)
+}
+
+func (u *uring) submit(r *request) {
+	u.active.Add(1)
+	u.enqueue(r)
+}
+
+// close waits for every request to settle, including the remainders of
+// partial transfers, before shutting the ring down.
+func (u *uring) close() {
+	u.active.Wait()
+	u.mu.Lock()
+	u.closing = true
+	u.mu.Unlock()
+	u.signal()
+	<-u.submitDone
+	select {
+	case <-u.reapDone:
+	case <-u.broken:
+		// the reaper may be blocked in the kernel waiting for completions
+		// that will never arrive, so the ring cannot be unmapped under it
+		select {
+		case <-u.reapDone:
+		default:
+			return
+		}
+	}
+	u.release()
+}
+
+// fail marks the ring as broken and rejects the requests in flight with err.
+// Requests submitted afterwards are rejected with err too.
+func (u *uring) fail(err error) {
+	u.mu.Lock()
+	if u.err != nil {
+		u.mu.Unlock()
+		return
+	}
+	u.err = err
+	close(u.broken)
+	ops := u.inflight
+	u.inflight = map[uint64]*uringOp{}
+	u.mu.Unlock()
+	for _, op := range ops {
+		u.abort(op.r, err)
+	}
+}
+
+// abort settles r with err without submitting it.
+func (u *uring) abort(r *request, err error) {
+	r.settle(r.n, err)
+	u.active.Done()
+}
+
+// enqueue queues r for the submit loop. Requests never wait for room in the
+// ring in the caller's goroutine.
+func (u *uring) enqueue(r *request) {
+	u.mu.Lock()
+	u.queue = append(u.queue, r)
+	u.mu.Unlock()
+	u.signal()
+}
+
+func (u *uring) signal() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (u *uring) enter(toSubmit, minComplete, flags uint32) (int, syscall.Errno) {
+	n, _, errno := syscall.Syscall6(sysIOURingEnter, uintptr(u.fd), uintptr(toSubmit), uintptr(minComplete), uintptr(flags), 0, 0)
+	return int(n), errno
+}
+
+// submitLoop batches every request that is already queued, new or retried,
+// into a single io_uring_enter call.
+func (u *uring) submitLoop() {
+	defer close(u.submitDone)
+	for range u.wake {
+		for {
+			rs, closing := u.take(u.sqSize)
+			if len(rs) == 0 {
+				if closing {
+					u.shutdown()
+					return
+				}
+				break
+			}
+			var n uint32
+			for _, r := range rs {
+				n += u.push(r)
+			}
+			u.flush(n)
+		}
+	}
+}
+
+// take pops up to max queued requests, and reports whether the ring is
+// shutting down.
+func (u *uring) take(max uint32) ([]*request, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	rs := u.queue
+	if uint32(len(rs)) > max {
+		rs = rs[:max:max]
+		u.queue = u.queue[max:]
+	} else {
+		u.queue = nil
+	}
+	return rs, u.closing
+}
+
+// shutdown submits the no-op that tells the reaper to stop.
+func (u *uring) shutdown() {
+	u.flush(u.push(nil))
+}
+
+// push writes a submission queue entry for r, or a no-op signalling shutdown
+// when r is nil, and reports how many entries it wrote. Once the ring is
+// broken nothing is written and r is rejected instead.
+func (u *uring) push(r *request) uint32 {
+	select {
+	case u.slots <- struct{}{}:
+	case <-u.broken:
+	}
+	var id uint64
+	var done int
+	op := &uringOp{r: r}
+	u.mu.Lock()
+	err := u.err
+	if err == nil && r != nil {
+		// read before the request is visible to the reaper
+		done = r.n
+		u.next++
+		id = u.next
+		u.inflight[id] = op
+	}
+	u.mu.Unlock()
+	if err != nil {
+		if r != nil {
+			u.abort(r, err)
+		}
+		return 0
+	}
+
+	tail := atomic.LoadUint32(u.sqTail)
+	idx := tail & u.sqMask
+	sqe := &u.sqes[idx]
+	*sqe = uringSQE{opcode: uringNop, userData: closeTag}
+	if r != nil {
+		sqe.fd = int32(fileFd(r.file))
+		sqe.userData = id
+		switch r.op {
+		case opRead, opWrite:
+			sqe.opcode = uringReadv
+			if r.op == opWrite {
+				sqe.opcode = uringWritev
+			}
+			if rest := r.buf[done:]; len(rest) > 0 {
+				op.iov.Base = &rest[0]
+				op.iov.SetLen(len(rest))
+			}
+			sqe.addr = uint64(uintptr(unsafe.Pointer(&op.iov)))
+			sqe.len = 1
+			sqe.off = uint64(r.off + int64(done))
+		case opFsync:
+			sqe.opcode = uringFsync
+		}
+	}
+	u.sqArray[idx] = idx
+	atomic.StoreUint32(u.sqTail, tail+1)
+	return 1
+}
+
+// flush hands n pushed entries over to the kernel.
+func (u *uring) flush(n uint32) {
+	for n > 0 {
+		submitted, errno := u.enter(n, 0, 0)
+		switch errno {
+		case 0:
+			n -= uint32(submitted)
+		case syscall.EINTR, syscall.EAGAIN, syscall.EBUSY:
+			runtime.Gosched()
+		default:
+			u.fail(os.NewSyscallError("io_uring_enter", errno))
+			return
+		}
+	}
+}
+
+// reapLoop settles requests as their completions arrive, until the shutdown
+// no-op has completed and nothing is left in flight, or the ring breaks.
+func (u *uring) reapLoop() {
+	defer close(u.reapDone)
+	closing := false
+	for {
+		head := atomic.LoadUint32(u.cqHead)
+		tail := atomic.LoadUint32(u.cqTail)
+		for ; head != tail; head++ {
+			cqe := u.cqes[head&u.cqMask]
+			atomic.StoreUint32(u.cqHead, head+1)
+			<-u.slots
+			if cqe.userData == closeTag {
+				closing = true
+				continue
+			}
+			u.mu.Lock()
+			op := u.inflight[cqe.userData]
+			delete(u.inflight, cqe.userData)
+			u.mu.Unlock()
+			if op == nil {
+				// already rejected when the ring broke
+				continue
+			}
+			var errno error
+			if cqe.res < 0 {
+				errno = syscall.Errno(-cqe.res)
+			}
+			if op.r.complete(int(cqe.res), errno) {
+				u.enqueue(op.r)
+			} else {
+				u.active.Done()
+			}
+		}
+		u.mu.Lock()
+		idle := len(u.inflight) == 0
+		broken := u.err != nil
+		u.mu.Unlock()
+		if broken || closing && idle {
+			return
+		}
+		if _, errno := u.enter(0, 1, enterGetEvents); errno != 0 && errno != syscall.EINTR {
+			u.fail(os.NewSyscallError("io_uring_enter", errno))
+			return
+		}
+	}
+}
+
+// fileFd returns the descriptor of f without switching it to blocking mode,
+// as f.Fd would.
+func fileFd(f *os.File) int {
+	fd := -1
+	if sc, err := f.SyscallConn(); err == nil {
+		_ = sc.Control(func(v uintptr) { fd = int(v) })
+	}
+	return fd
+}
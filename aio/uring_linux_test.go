@@ -0,0 +1,39 @@
+package aio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestURingBroken(t *testing.T) {
+	e := New(Config{Entries: 8})
+	if !e.IOURing() {
+		t.Skip("io_uring is unavailable")
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// with its descriptor gone, every io_uring_enter call fails
+	u := e.b.(*uring)
+	if err := syscall.Close(u.fd); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := e.WriteAt(f, []byte("x"), 0).Await(ctx)
+		var serr *os.SyscallError
+		if !errors.As(err, &serr) || serr.Err != syscall.EBADF {
+			t.Fatalf("expected io_uring_enter EBADF, got %v", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package aio
+
+import "errors"
+
+func newURing(uint32) (backend, error) {
+	return nil, errors.New("aio: io_uring is only available on linux")
+}
@@ -103,3 +103,26 @@ func All[T any](ctx context.Context, promises []Promise[T]) ([]T, error) {
 	}
 	return out, nil
 }
+
+// Race takes a slice of promises and will deliver the result of the first of
+// them to settle, whether it resolved or rejected. If ctx is done before any
+// promise settles, the context's error is returned.
+func Race[T any](ctx context.Context, promises []Promise[T]) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan *rp[T], len(promises))
+	for _, p := range promises {
+		p := p
+		go func() {
+			v, err := p.Await(ctx)
+			results <- &rp[T]{v: v, err: err}
+		}()
+	}
+	select {
+	case r := <-results:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zerov T
+		return zerov, ctx.Err()
+	}
+}
@@ -73,6 +73,40 @@ func TestAll(t *testing.T) {
 	requireEqual(t, ints, nil)
 }
 
+func TestRace(t *testing.T) {
+	promises := []Promise[int]{
+		NewPromise(func() (int, error) {
+			time.Sleep(time.Millisecond * 100)
+			return 42, nil
+		}),
+		NewPromise(func() (int, error) {
+			time.Sleep(time.Millisecond * 10)
+			return 43, nil
+		}),
+	}
+	ctx := context.Background()
+	v, err := Race(ctx, promises)
+	requireNoError(t, err)
+	requireEqual(t, 43, v)
+
+	promises = []Promise[int]{
+		NewPromise(func() (int, error) {
+			time.Sleep(time.Millisecond * 100)
+			return 42, nil
+		}),
+		NewPromise(func() (int, error) {
+			return 0, errors.New("doh!")
+		}),
+	}
+	_, err = Race(ctx, promises)
+	requireError(t, err)
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err = Race(ctxlowtimeout, []Promise[int]{})
+	requireEqual(t, context.DeadlineExceeded, err)
+}
+
 func TestResolve(t *testing.T) {
 	promise := Resolve("dff73ab5-5ff6-44f6-ba1e-7447ebf38675")
 	if !promise.Settled() {
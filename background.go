@@ -0,0 +1,189 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PanicError is the error of a background task that panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("async: task panicked: %v", e.Value)
+}
+
+// BackgroundError is a failure of a background task, as reported to the sink
+// of a Background runner.
+type BackgroundError struct {
+	Name string
+	Err  error
+
+	// Suppressed is the number of earlier failures of tasks with the same
+	// name that were not reported because of the rate limit.
+	Suppressed int
+}
+
+// BackgroundConfig configures a Background runner.
+type BackgroundConfig struct {
+	// Sink receives the failures of tasks. Defaults to logging them with the
+	// log package.
+	Sink func(BackgroundError)
+
+	// ReportLimit limits how often failures of tasks sharing a name are
+	// reported. Failures beyond the limit are counted and reported along
+	// with the next failure that is, or when the runner is closed. The zero
+	// value reports every failure.
+	ReportLimit Limit
+}
+
+// BackgroundTask describes a task that has not completed yet.
+type BackgroundTask struct {
+	ID      uint64
+	Name    string
+	Started time.Time
+}
+
+// Background runs fire-and-forget tasks. Nobody needs to await their
+// promises: every rejection and panic is routed to a central sink, so that
+// errors do not silently vanish.
+type Background struct {
+	cfg     BackgroundConfig
+	limiter *KeyedRateLimiter[string]
+
+	mu         sync.Mutex
+	closed     bool
+	nextID     uint64
+	tasks      map[uint64]BackgroundTask
+	suppressed map[string]BackgroundError
+	wg         sync.WaitGroup
+}
+
+// NewBackground creates a Background runner.
+func NewBackground(cfg BackgroundConfig) *Background {
+	if cfg.Sink == nil {
+		cfg.Sink = func(e BackgroundError) {
+			log.Printf("async: background task %q failed: %v (%d more suppressed)", e.Name, e.Err, e.Suppressed)
+		}
+	}
+	b := &Background{
+		cfg:        cfg,
+		tasks:      map[uint64]BackgroundTask{},
+		suppressed: map[string]BackgroundError{},
+	}
+	if cfg.ReportLimit != (Limit{}) {
+		b.limiter = NewKeyedRateLimiter[string](cfg.ReportLimit, time.Minute)
+	}
+	return b
+}
+
+// Go runs fn in the background under name.
+func (b *Background) Go(name string, fn func() error) Promise[struct{}] {
+	return GoBackground(b, name, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+}
+
+// GoBackground runs fn in the background under name, and returns a promise
+// of its result. A panic in fn rejects the promise with a *PanicError. Tasks
+// started after b was closed are rejected with ErrExecutorClosed.
+func GoBackground[T any](b *Background, name string, fn func() (T, error)) Promise[T] {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return Reject[T](ErrExecutorClosed)
+	}
+	b.nextID++
+	id := b.nextID
+	b.tasks[id] = BackgroundTask{ID: id, Name: name, Started: time.Now()}
+	b.wg.Add(1)
+	b.mu.Unlock()
+
+	return NewPromise(func() (v T, err error) {
+		defer b.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+			b.mu.Lock()
+			delete(b.tasks, id)
+			b.mu.Unlock()
+			if err != nil {
+				b.report(name, err)
+			}
+		}()
+		return fn()
+	})
+}
+
+func (b *Background) report(name string, err error) {
+	e := BackgroundError{Name: name, Err: err}
+	if b.limiter != nil {
+		b.mu.Lock()
+		if !b.limiter.Allow(name) {
+			s := b.suppressed[name]
+			b.suppressed[name] = BackgroundError{Name: name, Err: err, Suppressed: s.Suppressed + 1}
+			b.mu.Unlock()
+			return
+		}
+		e.Suppressed = b.suppressed[name].Suppressed
+		delete(b.suppressed, name)
+		b.mu.Unlock()
+	}
+	b.cfg.Sink(e)
+}
+
+// Tasks lists the tasks that have not completed yet, oldest first.
+func (b *Background) Tasks() []BackgroundTask {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]BackgroundTask, 0, len(b.tasks))
+	for _, t := range b.tasks {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Close stops accepting tasks and waits for the outstanding ones to complete,
+// or for ctx to be done. Failures that were suppressed by the rate limit are
+// reported once all tasks have completed.
+func (b *Background) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+
+	b.mu.Lock()
+	suppressed := b.suppressed
+	b.suppressed = map[string]BackgroundError{}
+	b.mu.Unlock()
+	names := make([]string, 0, len(suppressed))
+	for name := range suppressed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e := suppressed[name]
+		e.Suppressed--
+		b.cfg.Sink(e)
+	}
+	return nil
+}
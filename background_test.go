@@ -0,0 +1,111 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sinkRecorder struct {
+	mu   sync.Mutex
+	errs []BackgroundError
+}
+
+func (r *sinkRecorder) sink(e BackgroundError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, e)
+}
+
+func (r *sinkRecorder) reported() []BackgroundError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]BackgroundError(nil), r.errs...)
+}
+
+func TestBackground(t *testing.T) {
+	ctx := context.Background()
+	var rec sinkRecorder
+	b := NewBackground(BackgroundConfig{Sink: rec.sink})
+
+	v, err := GoBackground(b, "ok", func() (int, error) { return 1, nil }).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+
+	boom := errors.New("boom")
+	_, err = b.Go("fail", func() error { return boom }).Await(ctx)
+	requireEqual(t, boom, err)
+
+	_, err = b.Go("panic", func() error { panic("oops") }).Await(ctx)
+	var pe *PanicError
+	requireEqual(t, true, errors.As(err, &pe))
+	requireEqual[any](t, "oops", pe.Value)
+	requireEqual(t, true, len(pe.Stack) > 0)
+
+	requireNoError(t, b.Close(ctx))
+	requireEqual(t, []BackgroundError{
+		{Name: "fail", Err: boom},
+		{Name: "panic", Err: pe},
+	}, rec.reported())
+
+	_, err = b.Go("late", func() error { return nil }).Await(ctx)
+	requireEqual(t, ErrExecutorClosed, err)
+}
+
+func TestBackgroundTasksAndClose(t *testing.T) {
+	ctx := context.Background()
+	b := NewBackground(BackgroundConfig{Sink: func(BackgroundError) {}})
+
+	release := make(chan struct{})
+	b.Go("first", func() error { <-release; return nil })
+	b.Go("second", func() error { <-release; return nil })
+	var names []string
+	for _, task := range b.Tasks() {
+		names = append(names, task.Name)
+	}
+	requireEqual(t, []string{"first", "second"}, names)
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	requireEqual(t, context.DeadlineExceeded, b.Close(ctxlowtimeout))
+	close(release)
+	requireNoError(t, b.Close(ctx))
+	requireEqual(t, 0, len(b.Tasks()))
+}
+
+func TestBackgroundReportLimit(t *testing.T) {
+	ctx := context.Background()
+	var rec sinkRecorder
+	b := NewBackground(BackgroundConfig{
+		Sink:        rec.sink,
+		ReportLimit: Limit{Rate: 1, Burst: 1},
+	})
+	now := time.Unix(0, 0)
+	b.limiter.now = func() time.Time { return now }
+
+	errs := map[string]error{"a": errors.New("a"), "b": errors.New("b")}
+	fail := func(name string) {
+		_, err := b.Go(name, func() error { return errs[name] }).Await(ctx)
+		requireEqual(t, errs[name], err)
+	}
+	for i := 0; i < 4; i++ {
+		fail("a")
+	}
+	fail("b")
+	requireEqual(t, []BackgroundError{
+		{Name: "a", Err: errs["a"]},
+		{Name: "b", Err: errs["b"]},
+	}, rec.reported())
+
+	now = now.Add(time.Second)
+	for i := 0; i < 3; i++ {
+		fail("a")
+	}
+	requireEqual(t, BackgroundError{Name: "a", Err: errs["a"], Suppressed: 3}, rec.reported()[2])
+
+	requireNoError(t, b.Close(ctx))
+	requireEqual(t, 4, len(rec.reported()))
+	requireEqual(t, BackgroundError{Name: "a", Err: errs["a"], Suppressed: 1}, rec.reported()[3])
+}
@@ -0,0 +1,121 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned by promises that Spawn could not start
+// within the budget of their context.
+var ErrBudgetExceeded = errors.New("async: budget exceeded")
+
+// Budget limits the promises that Spawn may start on behalf of a context,
+// so that a single request cannot monopolise the process with unbounded
+// fan-out.
+type Budget struct {
+	// MaxConcurrent is the number of promises that may run at the same time.
+	// Zero means no limit.
+	MaxConcurrent int
+
+	// MaxTotal is the number of promises that may be spawned overall. Zero
+	// means no limit.
+	MaxTotal int
+
+	// Queue makes promises wait for a running one to finish once
+	// MaxConcurrent is reached, instead of being rejected with
+	// ErrBudgetExceeded. Queued promises do not hold a goroutine.
+	Queue bool
+}
+
+type budgetCtxKey struct{}
+
+type budgetState struct {
+	cfg Budget
+
+	mu      sync.Mutex
+	running int
+	total   int
+	queue   []func() bool
+}
+
+// WithBudget returns a copy of ctx that carries b. A budget applies to every
+// promise spawned with the returned context or one derived from it, unless a
+// nested budget is installed.
+func WithBudget(ctx context.Context, b Budget) context.Context {
+	return context.WithValue(ctx, budgetCtxKey{}, &budgetState{cfg: b})
+}
+
+// Spawn is like NewPromise, but charges the promise to the budget carried by
+// ctx, if any. Once the budget is exhausted, the promise is either queued or
+// rejected with ErrBudgetExceeded. A queued promise whose ctx is done by the
+// time it could start is rejected with the context's error.
+func Spawn[T any](ctx context.Context, fn func() (T, error)) Promise[T] {
+	b, ok := ctx.Value(budgetCtxKey{}).(*budgetState)
+	if !ok {
+		return NewPromise(fn)
+	}
+	c := &syncPromise[T]{
+		done: make(chan struct{}),
+	}
+	// start runs fn in the slot it was given, or rejects the promise and
+	// reports false if ctx is done, leaving the slot to the caller
+	start := func() bool {
+		if err := ctx.Err(); err != nil {
+			c.err = err
+			close(c.done)
+			return false
+		}
+		go func() {
+			c.v, c.err = fn()
+			// release the slot first, so that it is available to whoever
+			// observes the promise settling
+			b.finish()
+			close(c.done)
+		}()
+		return true
+	}
+
+	b.mu.Lock()
+	if b.cfg.MaxTotal > 0 && b.total >= b.cfg.MaxTotal {
+		b.mu.Unlock()
+		return Reject[T](ErrBudgetExceeded)
+	}
+	if b.cfg.MaxConcurrent > 0 && b.running >= b.cfg.MaxConcurrent {
+		if !b.cfg.Queue {
+			b.mu.Unlock()
+			return Reject[T](ErrBudgetExceeded)
+		}
+		b.total++
+		b.queue = append(b.queue, start)
+		b.mu.Unlock()
+		return c
+	}
+	b.total++
+	b.running++
+	b.mu.Unlock()
+	if !start() {
+		b.finish()
+	}
+	return c
+}
+
+// finish releases the slot of a promise that settled, handing it over to the
+// next queued promise that can still start, if there is one.
+func (b *budgetState) finish() {
+	for {
+		b.mu.Lock()
+		if len(b.queue) == 0 {
+			b.running--
+			b.mu.Unlock()
+			return
+		}
+		next := b.queue[0]
+		b.queue[0] = nil
+		b.queue = b.queue[1:]
+		b.mu.Unlock()
+		if next() {
+			return
+		}
+	}
+}
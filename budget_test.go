@@ -0,0 +1,84 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpawn(t *testing.T) {
+	ctx := WithBudget(context.Background(), Budget{MaxConcurrent: 2, MaxTotal: 3})
+	release := make(chan struct{})
+	wait := func() (int, error) {
+		<-release
+		return 42, nil
+	}
+	first, second := Spawn(ctx, wait), Spawn(ctx, wait)
+	_, err := Spawn(ctx, wait).Await(ctx)
+	requireEqual(t, ErrBudgetExceeded, err) // too many running
+	close(release)
+	_, err = All(ctx, []Promise[int]{first, second})
+	requireNoError(t, err)
+
+	_, err = Spawn(ctx, wait).Await(ctx)
+	requireNoError(t, err)
+	_, err = Spawn(ctx, wait).Await(ctx)
+	requireEqual(t, ErrBudgetExceeded, err) // too many overall
+}
+
+func TestSpawnQueue(t *testing.T) {
+	ctx := WithBudget(context.Background(), Budget{MaxConcurrent: 2, Queue: true})
+	var running, peak int32
+	task := func() (int, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		atomic.AddInt32(&running, -1)
+		return 1, nil
+	}
+	promises := make([]Promise[int], 10)
+	for i := range promises {
+		promises[i] = Spawn(ctx, task)
+	}
+	vs, err := All(ctx, promises)
+	requireNoError(t, err)
+	requireEqual(t, 10, len(vs))
+	requireEqual(t, int32(2), atomic.LoadInt32(&peak))
+
+	// without a budget Spawn behaves like NewPromise
+	v, err := Spawn(context.Background(), task).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+}
+
+func TestSpawnQueueCanceled(t *testing.T) {
+	ctx := WithBudget(context.Background(), Budget{MaxConcurrent: 1, Queue: true})
+	release := make(chan struct{})
+	blocker := Spawn(ctx, func() (int, error) {
+		<-release
+		return 0, nil
+	})
+	canceled, cancel := context.WithCancel(ctx)
+	promises := make([]Promise[int], 10000)
+	for i := range promises {
+		promises[i] = Spawn(canceled, func() (int, error) { return 1, nil })
+	}
+	cancel()
+	close(release)
+	_, err := blocker.Await(ctx)
+	requireNoError(t, err)
+	for _, p := range promises {
+		_, err := p.Await(ctx)
+		requireEqual(t, context.Canceled, err)
+	}
+
+	v, err := Spawn(ctx, func() (int, error) { return 1, nil }).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+}
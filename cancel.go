@@ -0,0 +1,39 @@
+package async
+
+import "context"
+
+// CancelablePromise is a Promise whose work can be abandoned before it
+// settles.
+type CancelablePromise[T any] interface {
+	Promise[T]
+
+	// Cancel cancels the context handed to the promise's function. It has no
+	// effect once the promise has settled.
+	Cancel()
+}
+
+type cancelablePromise[T any] struct {
+	*syncPromise[T]
+	cancel context.CancelFunc
+}
+
+func (c *cancelablePromise[T]) Cancel() {
+	c.cancel()
+}
+
+// NewCancelablePromise is like NewPromise, but fn receives a context derived
+// from ctx that is canceled when Cancel is called. fn is expected to return
+// promptly once its context is done.
+func NewCancelablePromise[T any](ctx context.Context, fn func(context.Context) (T, error)) CancelablePromise[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &cancelablePromise[T]{
+		syncPromise: &syncPromise[T]{done: make(chan struct{})},
+		cancel:      cancel,
+	}
+	go func() {
+		defer cancel()
+		c.v, c.err = fn(ctx)
+		close(c.done)
+	}()
+	return c
+}
@@ -0,0 +1,29 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewCancelablePromise(t *testing.T) {
+	ctx := context.Background()
+	promise := NewCancelablePromise(ctx, func(ctx context.Context) (string, error) {
+		return "foo", nil
+	})
+	v, err := promise.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "foo", v)
+
+	promise = NewCancelablePromise(ctx, func(ctx context.Context) (string, error) {
+		select {
+		case <-time.After(time.Second):
+			return "too slow", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+	promise.Cancel()
+	_, err = promise.Await(ctx)
+	requireEqual(t, context.Canceled, err)
+}
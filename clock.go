@@ -0,0 +1,39 @@
+package async
+
+import "time"
+
+// Clock is the source of time for the time-based types of this package. It
+// can be replaced to control time in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C delivers the time once the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It reports false if the timer had
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+
+func (t systemTimer) Stop() bool { return t.t.Stop() }
@@ -0,0 +1,124 @@
+package async
+
+import (
+	"context"
+	"reflect"
+)
+
+type copyPromise[T any] struct {
+	p     Promise[T]
+	clone func(T) T
+}
+
+func (c *copyPromise[T]) Settled() bool {
+	return c.p.Settled()
+}
+
+func (c *copyPromise[T]) Await(ctx context.Context) (T, error) {
+	v, err := c.p.Await(ctx)
+	if err != nil {
+		return v, err
+	}
+	return c.clone(v), nil
+}
+
+// CopyOnAwait wraps p so that every Await call receives its own copy of the
+// value, made with clone, or with DeepCopy if clone is nil. This allows
+// concurrent consumers of the same promise to mutate their result without
+// racing with each other.
+func CopyOnAwait[T any](p Promise[T], clone func(T) T) Promise[T] {
+	if clone == nil {
+		clone = DeepCopy[T]
+	}
+	return &copyPromise[T]{p: p, clone: clone}
+}
+
+// DeepCopy returns a copy of v that shares no pointers, slices, maps or
+// interface values with it, preserving cycles. Channels and functions are
+// shared, and unexported struct fields are copied as they are, since they
+// cannot be inspected: values relying on them to hold mutable state need a
+// dedicated clone function.
+func DeepCopy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	c := copier{seen: map[copyKey]reflect.Value{}}
+	c.copy(dst, src)
+	return dst.Interface().(T)
+}
+
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type copier struct {
+	seen map[copyKey]reflect.Value
+}
+
+// copy sets dst, which must be settable, to a deep copy of src.
+func (c copier) copy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := copyKey{ptr: src.Pointer(), typ: src.Type()}
+		if p, ok := c.seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[key] = p
+		c.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		c.copy(e, src.Elem())
+		dst.Set(e)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := copyKey{ptr: src.Pointer(), typ: src.Type()}
+		if m, ok := c.seen[key]; ok {
+			dst.Set(m)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.seen[key] = m
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			c.copy(k, iter.Key())
+			v := reflect.New(src.Type().Elem()).Elem()
+			c.copy(v, iter.Value())
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if f := dst.Field(i); f.CanSet() {
+				c.copy(f, src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,63 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type copyNode struct {
+	Name     string
+	Tags     []string
+	Attrs    map[string]any
+	Next     *copyNode
+	Created  time.Time
+	internal []int
+}
+
+func TestDeepCopy(t *testing.T) {
+	orig := &copyNode{
+		Name:     "a",
+		Tags:     []string{"x"},
+		Attrs:    map[string]any{"k": []int{1}},
+		Created:  time.Unix(0, 0),
+		internal: []int{1},
+	}
+	orig.Next = orig
+
+	cp := DeepCopy(orig)
+	requireEqual(t, orig.Name, cp.Name)
+	requireEqual(t, true, cp.Next == cp) // cycles are preserved
+	requireEqual(t, orig.Created, cp.Created)
+
+	cp.Tags[0] = "y"
+	cp.Attrs["k"].([]int)[0] = 2
+	cp.Attrs["new"] = true
+	requireEqual(t, []string{"x"}, orig.Tags)
+	requireEqual[any](t, []int{1}, orig.Attrs["k"])
+	requireEqual(t, 1, len(orig.Attrs))
+
+	requireEqual(t, []int{1, 2}, DeepCopy([]int{1, 2}))
+	requireEqual(t, ([]int)(nil), DeepCopy([]int(nil)))
+}
+
+func TestCopyOnAwait(t *testing.T) {
+	ctx := context.Background()
+	promise := CopyOnAwait(Resolve([]int{1, 2, 3}), nil)
+	a, err := promise.Await(ctx)
+	requireNoError(t, err)
+	a[0] = 42
+	b, err := promise.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2, 3}, b)
+	requireEqual(t, true, promise.Settled())
+
+	clones := 0
+	promise = CopyOnAwait(Resolve([]int{1}), func(v []int) []int {
+		clones++
+		return append([]int(nil), v...)
+	})
+	_, err = promise.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, clones)
+}
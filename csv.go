@@ -0,0 +1,61 @@
+package async
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// DecodeCSV returns a stream that reads records from r and converts each of
+// them into a T with parse. Configure r, for instance its Comma, before
+// handing it over; a header, if any, should be read beforehand. A record that
+// fails to read or parse fails the stream.
+func DecodeCSV[T any](r *csv.Reader, parse func(record []string) (T, error)) Stream[T] {
+	var failed error
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		var zerov T
+		if failed != nil {
+			return zerov, failed
+		}
+		if err := ctx.Err(); err != nil {
+			return zerov, err
+		}
+		record, err := r.Read()
+		if err == nil {
+			var v T
+			if v, err = parse(record); err == nil {
+				return v, nil
+			}
+		}
+		failed = err
+		return zerov, err
+	})
+}
+
+// EncodeCSV writes every outcome of s to w until s is exhausted. Each record
+// holds the outcome's index, its error message, which is empty on success,
+// followed by the fields returned by format for successful outcomes.
+func EncodeCSV[T any](ctx context.Context, w *csv.Writer, s Stream[Outcome[T]], format func(T) []string) error {
+	defer w.Flush()
+	for {
+		o, err := s.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			w.Flush()
+			return w.Error()
+		}
+		if err != nil {
+			return err
+		}
+		record := []string{strconv.Itoa(o.Index), ""}
+		if o.Err != nil {
+			record[1] = o.Err.Error()
+		} else {
+			record = append(record, format(o.Value)...)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+}
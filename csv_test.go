@@ -0,0 +1,39 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	ctx := context.Background()
+	parse := func(record []string) (int, error) {
+		return strconv.Atoi(record[1])
+	}
+	vs, err := Collect(ctx, DecodeCSV(csv.NewReader(strings.NewReader("a,1\nb,2\n")), parse))
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2}, vs)
+
+	vs, err = Collect(ctx, DecodeCSV(csv.NewReader(strings.NewReader("a,1\nb,x\nc,3\n")), parse))
+	requireError(t, err)
+	requireEqual(t, []int{1}, vs)
+}
+
+func TestEncodeCSV(t *testing.T) {
+	ctx := context.Background()
+	promises := []Promise[int]{
+		Resolve(42),
+		Reject[int](errors.New("darn")),
+	}
+	var buf bytes.Buffer
+	err := EncodeCSV(ctx, csv.NewWriter(&buf), Outcomes(ctx, FromSlice(promises), InputOrder), func(v int) []string {
+		return []string{strconv.Itoa(v)}
+	})
+	requireNoError(t, err)
+	requireEqual(t, "0,,42\n1,darn\n", buf.String())
+}
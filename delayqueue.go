@@ -0,0 +1,164 @@
+package async
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue holds items until their scheduled time, for instance to retry
+// failed tasks with a backoff without holding on to a worker in the meantime.
+type DelayQueue[T any] struct {
+	clock Clock
+
+	mu      sync.Mutex
+	items   delayHeap[T]
+	seq     uint64
+	changed chan struct{} // closed whenever the earliest item changes
+}
+
+// Delayed is the handle of an item scheduled on a DelayQueue.
+type Delayed[T any] struct {
+	q     *DelayQueue[T]
+	v     T
+	at    time.Time
+	seq   uint64
+	index int // position in the queue, -1 once taken or canceled
+}
+
+// At reports when the item becomes available.
+func (d *Delayed[T]) At() time.Time {
+	return d.at
+}
+
+// Cancel removes the item from its queue. It reports false if the item was
+// already taken or canceled.
+func (d *Delayed[T]) Cancel() bool {
+	q := d.q
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if d.index < 0 {
+		return false
+	}
+	first := d.index == 0
+	heap.Remove(&q.items, d.index)
+	if first {
+		q.notify()
+	}
+	return true
+}
+
+// NewDelayQueue creates an empty queue that reads time from clock, or from
+// SystemClock if clock is nil.
+func NewDelayQueue[T any](clock Clock) *DelayQueue[T] {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &DelayQueue[T]{
+		clock:   clock,
+		changed: make(chan struct{}),
+	}
+}
+
+// Put schedules v to become available after delay.
+func (q *DelayQueue[T]) Put(v T, delay time.Duration) *Delayed[T] {
+	return q.PutAt(v, q.clock.Now().Add(delay))
+}
+
+// PutAt schedules v to become available at t. Items scheduled for the same
+// time become available in the order they were put.
+func (q *DelayQueue[T]) PutAt(v T, t time.Time) *Delayed[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	d := &Delayed[T]{q: q, v: v, at: t, seq: q.seq}
+	heap.Push(&q.items, d)
+	if d.index == 0 {
+		q.notify()
+	}
+	return d
+}
+
+// notify wakes up every Take call so that they reconsider the earliest item.
+// Must be called with q.mu held.
+func (q *DelayQueue[T]) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// Len reports the number of scheduled items, available or not.
+func (q *DelayQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Take blocks until an item is available and removes it from the queue. If
+// ctx is done first, the context's error is returned.
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		var timer <-chan time.Time
+		var t Timer
+		if len(q.items) > 0 {
+			d := q.items[0]
+			wait := d.at.Sub(q.clock.Now())
+			if wait <= 0 {
+				heap.Pop(&q.items)
+				q.mu.Unlock()
+				return d.v, nil
+			}
+			t = q.clock.NewTimer(wait)
+			timer = t.C()
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if t != nil {
+				t.Stop()
+			}
+			var zerov T
+			return zerov, ctx.Err()
+		case <-timer:
+		case <-changed:
+			if t != nil {
+				t.Stop()
+			}
+		}
+	}
+}
+
+type delayHeap[T any] []*Delayed[T]
+
+func (h delayHeap[T]) Len() int { return len(h) }
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	if !h[i].at.Equal(h[j].at) {
+		return h[i].at.Before(h[j].at)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap[T]) Push(x any) {
+	d := x.(*Delayed[T])
+	d.index = len(*h)
+	*h = append(*h, d)
+}
+
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	old[len(old)-1] = nil
+	d.index = -1
+	*h = old[:len(old)-1]
+	return d
+}
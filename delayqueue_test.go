@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDelayQueue(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue[string](clock)
+	ctx := context.Background()
+
+	q.Put("later", time.Minute)
+	q.Put("soon", time.Second)
+	canceled := q.Put("never", time.Millisecond)
+	requireEqual(t, true, canceled.Cancel())
+	requireEqual(t, false, canceled.Cancel())
+	requireEqual(t, 2, q.Len())
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err := q.Take(ctxlowtimeout)
+	requireEqual(t, context.DeadlineExceeded, err)
+
+	taken := NewPromise(func() (string, error) {
+		return q.Take(ctx)
+	})
+	clock.WaitForTimers(1)
+	clock.Advance(time.Second)
+	v, err := taken.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "soon", v)
+
+	// an earlier item put while Take is waiting is delivered first
+	taken = NewPromise(func() (string, error) {
+		return q.Take(ctx)
+	})
+	clock.WaitForTimers(1)
+	q.Put("retry", time.Second)
+	clock.Advance(time.Second)
+	v, err = taken.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "retry", v)
+	requireEqual(t, 1, q.Len())
+}
+
+func TestDelayQueueSystemClock(t *testing.T) {
+	q := NewDelayQueue[int](nil)
+	ctx := context.Background()
+	start := time.Now()
+	q.Put(42, time.Millisecond*50)
+	v, err := q.Take(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 42, v)
+	if elapsed := time.Since(start); elapsed < time.Millisecond*50 {
+		t.Fatalf("item was taken too early, after %s", elapsed)
+	}
+}
@@ -0,0 +1,130 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Exchanger is a synchronization point at which two goroutines swap values.
+// The zero value is ready to use.
+type Exchanger[T any] struct {
+	mu      sync.Mutex
+	waiting *exchangeOffer[T]
+}
+
+type exchangeOffer[T any] struct {
+	v       T
+	promise *syncPromise[T]
+}
+
+// Exchange offers v and returns a promise for the value offered by the next
+// goroutine to call Exchange. If ctx is done before a partner arrives, the
+// offer is withdrawn and the promise is rejected with ctx.Err().
+func (e *Exchanger[T]) Exchange(ctx context.Context, v T) Promise[T] {
+	if err := ctx.Err(); err != nil {
+		return Reject[T](err)
+	}
+	e.mu.Lock()
+	if partner := e.waiting; partner != nil {
+		e.waiting = nil
+		e.mu.Unlock()
+		partner.promise.v = v
+		close(partner.promise.done)
+		return Resolve(partner.v)
+	}
+	offer := &exchangeOffer[T]{
+		v:       v,
+		promise: &syncPromise[T]{done: make(chan struct{})},
+	}
+	e.waiting = offer
+	e.mu.Unlock()
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				if e.waiting == offer {
+					e.waiting = nil
+					offer.promise.err = ctx.Err()
+					close(offer.promise.done)
+				}
+			case <-offer.promise.done:
+			}
+		}()
+	}
+	return offer.promise
+}
+
+// Rendezvous is a reusable synchronization point for a fixed number of
+// parties. Once every party has arrived, each of them receives the values
+// offered by all parties, in arrival order, and the next round begins.
+type Rendezvous[T any] struct {
+	n       int
+	mu      sync.Mutex
+	arrived []*rendezvousParty[T]
+}
+
+type rendezvousParty[T any] struct {
+	v       T
+	promise *syncPromise[[]T]
+}
+
+// NewRendezvous creates a Rendezvous for n parties.
+func NewRendezvous[T any](n int) *Rendezvous[T] {
+	return &Rendezvous[T]{n: n}
+}
+
+// Arrive offers v for the current round and returns a promise for the values
+// of every party of that round. Each party receives its own copy of the
+// values. If ctx is done before the round is complete, the party leaves the
+// round and the promise is rejected with ctx.Err().
+func (r *Rendezvous[T]) Arrive(ctx context.Context, v T) Promise[[]T] {
+	if err := ctx.Err(); err != nil {
+		return Reject[[]T](err)
+	}
+	party := &rendezvousParty[T]{
+		v:       v,
+		promise: &syncPromise[[]T]{done: make(chan struct{})},
+	}
+	r.mu.Lock()
+	r.arrived = append(r.arrived, party)
+	if len(r.arrived) >= r.n {
+		round := r.arrived
+		r.arrived = nil
+		r.mu.Unlock()
+		values := make([]T, 0, len(round))
+		for _, p := range round {
+			values = append(values, p.v)
+		}
+		for _, p := range round {
+			p.promise.v = append([]T(nil), values...)
+			close(p.promise.done)
+		}
+		return party.promise
+	}
+	r.mu.Unlock()
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				r.leave(party, ctx.Err())
+			case <-party.promise.done:
+			}
+		}()
+	}
+	return party.promise
+}
+
+func (r *Rendezvous[T]) leave(party *rendezvousParty[T], err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, p := range r.arrived {
+		if p == party {
+			r.arrived = append(r.arrived[:i], r.arrived[i+1:]...)
+			party.promise.err = err
+			close(party.promise.done)
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExchanger(t *testing.T) {
+	var e Exchanger[string]
+	ctx := context.Background()
+
+	a := e.Exchange(ctx, "a")
+	requireEqual(t, false, a.Settled())
+	b := e.Exchange(ctx, "b")
+	v, err := a.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "b", v)
+	v, err = b.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "a", v)
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err = e.Exchange(ctxlowtimeout, "too lonely").Await(ctx)
+	requireEqual(t, context.DeadlineExceeded, err)
+
+	// the withdrawn offer must not be picked up
+	c := e.Exchange(ctx, "c")
+	d := e.Exchange(ctx, "d")
+	v, err = c.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "d", v)
+	v, err = d.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "c", v)
+}
+
+func TestRendezvous(t *testing.T) {
+	r := NewRendezvous[int](3)
+	ctx := context.Background()
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err := r.Arrive(ctxlowtimeout, 0).Await(ctx)
+	requireEqual(t, context.DeadlineExceeded, err)
+
+	promises := []Promise[[]int]{r.Arrive(ctx, 1), r.Arrive(ctx, 2)}
+	requireEqual(t, false, promises[0].Settled())
+	promises = append(promises, r.Arrive(ctx, 3))
+	results, err := All(ctx, promises)
+	requireNoError(t, err)
+	requireEqual(t, [][]int{{1, 2, 3}, {1, 2, 3}, {1, 2, 3}}, results)
+
+	// next round
+	p := r.Arrive(ctx, 4)
+	requireEqual(t, false, p.Settled())
+}
@@ -0,0 +1,201 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// Readable returns a promise that settles once fd is ready for reading, has
+// hung up, or is in an error state. It is meant for raw descriptors that are
+// not managed by the Go runtime network poller, such as pipes, eventfd or
+// signalfd, and only reports readiness: the caller still performs the read.
+// The promise is rejected with ctx.Err() if ctx is done first.
+func Readable(ctx context.Context, fd int) Promise[struct{}] {
+	return waitFd(ctx, fd, syscall.EPOLLIN)
+}
+
+// Writable returns a promise that settles once fd is ready for writing. See
+// Readable.
+func Writable(ctx context.Context, fd int) Promise[struct{}] {
+	return waitFd(ctx, fd, syscall.EPOLLOUT)
+}
+
+type fdWaiter struct {
+	events  uint32
+	promise *syncPromise[struct{}]
+}
+
+// fdPoller multiplexes every pending Readable and Writable call onto a single
+// epoll instance, watched by a single goroutine.
+type fdPoller struct {
+	epfd int
+
+	mu      sync.Mutex
+	waiters map[int32]map[*fdWaiter]struct{}
+	err     error // set once epoll_wait failed for good
+}
+
+var (
+	fdPollerOnce sync.Once
+	fdPollerInst *fdPoller
+	fdPollerErr  error
+)
+
+func getFdPoller() (*fdPoller, error) {
+	fdPollerOnce.Do(func() {
+		epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+		if err != nil {
+			fdPollerErr = os.NewSyscallError("epoll_create1", err)
+			return
+		}
+		fdPollerInst = &fdPoller{
+			epfd:    epfd,
+			waiters: map[int32]map[*fdWaiter]struct{}{},
+		}
+		go fdPollerInst.run()
+	})
+	return fdPollerInst, fdPollerErr
+}
+
+func waitFd(ctx context.Context, fd int, events uint32) Promise[struct{}] {
+	if err := ctx.Err(); err != nil {
+		return Reject[struct{}](err)
+	}
+	p, err := getFdPoller()
+	if err != nil {
+		return Reject[struct{}](err)
+	}
+	w := &fdWaiter{
+		events:  events,
+		promise: &syncPromise[struct{}]{done: make(chan struct{})},
+	}
+	if err := p.add(int32(fd), w); err != nil {
+		return Reject[struct{}](err)
+	}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				p.remove(int32(fd), w, ctx.Err())
+			case <-w.promise.done:
+			}
+		}()
+	}
+	return w.promise
+}
+
+func (p *fdPoller) add(fd int32, w *fdWaiter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	set := p.waiters[fd]
+	op := syscall.EPOLL_CTL_MOD
+	if len(set) == 0 {
+		set = map[*fdWaiter]struct{}{}
+		p.waiters[fd] = set
+		op = syscall.EPOLL_CTL_ADD
+	}
+	set[w] = struct{}{}
+	err := p.ctl(op, fd, set)
+	if op == syscall.EPOLL_CTL_MOD && errors.Is(err, syscall.ENOENT) {
+		// the descriptor was closed since the other waiters registered, which
+		// dropped it from the epoll set, and its number has been reused
+		err = p.ctl(syscall.EPOLL_CTL_ADD, fd, set)
+	}
+	if err != nil {
+		delete(set, w)
+		if len(set) == 0 {
+			delete(p.waiters, fd)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove settles w with err, unless it has already been settled.
+func (p *fdPoller) remove(fd int32, w *fdWaiter, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	set := p.waiters[fd]
+	if _, ok := set[w]; !ok {
+		return
+	}
+	delete(set, w)
+	p.rearm(fd, set)
+	w.promise.err = err
+	close(w.promise.done)
+}
+
+// ctl registers fd for the union of the events its waiters are interested
+// in. Must be called with p.mu held.
+func (p *fdPoller) ctl(op int, fd int32, set map[*fdWaiter]struct{}) error {
+	ev := syscall.EpollEvent{Fd: fd}
+	for w := range set {
+		ev.Events |= w.events
+	}
+	if err := syscall.EpollCtl(p.epfd, op, int(fd), &ev); err != nil {
+		return os.NewSyscallError("epoll_ctl", err)
+	}
+	return nil
+}
+
+// rearm updates the registration of fd after waiters were removed. Must be
+// called with p.mu held.
+func (p *fdPoller) rearm(fd int32, set map[*fdWaiter]struct{}) {
+	if len(set) == 0 {
+		delete(p.waiters, fd)
+		// the descriptor may already have been closed, which removes it from
+		// the epoll set on its own
+		_ = syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_DEL, int(fd), nil)
+		return
+	}
+	_ = p.ctl(syscall.EPOLL_CTL_MOD, fd, set)
+}
+
+func (p *fdPoller) run() {
+	events := make([]syscall.EpollEvent, 128)
+	for {
+		n, err := syscall.EpollWait(p.epfd, events, -1)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			p.fail(os.NewSyscallError("epoll_wait", err))
+			return
+		}
+		p.mu.Lock()
+		for _, ev := range events[:n] {
+			set := p.waiters[ev.Fd]
+			for w := range set {
+				if ev.Events&(w.events|syscall.EPOLLERR|syscall.EPOLLHUP) == 0 {
+					continue
+				}
+				delete(set, w)
+				close(w.promise.done)
+			}
+			if set != nil {
+				p.rearm(ev.Fd, set)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// fail rejects every waiter with err, as well as any later call.
+func (p *fdPoller) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+	for fd, set := range p.waiters {
+		for w := range set {
+			w.promise.err = err
+			close(w.promise.done)
+		}
+		delete(p.waiters, fd)
+	}
+}
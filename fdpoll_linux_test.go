@@ -0,0 +1,78 @@
+package async
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func rawFd(t *testing.T, f *os.File) int {
+	t.Helper()
+	sc, err := f.SyscallConn()
+	requireNoError(t, err)
+	var fd int
+	requireNoError(t, sc.Control(func(v uintptr) { fd = int(v) }))
+	return fd
+}
+
+func TestReadable(t *testing.T) {
+	r, w, err := os.Pipe()
+	requireNoError(t, err)
+	defer r.Close()
+	defer w.Close()
+	ctx := context.Background()
+
+	_, err = Writable(ctx, rawFd(t, w)).Await(ctx)
+	requireNoError(t, err)
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	promise := Readable(ctxlowtimeout, rawFd(t, r))
+	_, err = promise.Await(ctx)
+	requireEqual(t, context.DeadlineExceeded, err)
+
+	promise = Readable(ctx, rawFd(t, r))
+	time.Sleep(time.Millisecond * 10)
+	requireEqual(t, false, promise.Settled())
+	_, err = w.Write([]byte("x"))
+	requireNoError(t, err)
+	_, err = promise.Await(ctx)
+	requireNoError(t, err)
+
+	promises := []Promise[struct{}]{Readable(ctx, rawFd(t, r)), Readable(ctx, rawFd(t, r))}
+	_, err = All(ctx, promises)
+	requireNoError(t, err)
+}
+
+func TestReadableReusedFd(t *testing.T) {
+	ctx := context.Background()
+	r, w, err := os.Pipe()
+	requireNoError(t, err)
+	defer w.Close()
+	fd := rawFd(t, r)
+	// the waiter outlives the descriptor, which the kernel drops from the
+	// epoll set once it is closed
+	Readable(ctx, fd)
+	requireNoError(t, r.Close())
+
+	for i := 0; i < 16; i++ {
+		r, w, err := os.Pipe()
+		requireNoError(t, err)
+		defer r.Close()
+		defer w.Close()
+		switch fd {
+		case rawFd(t, r):
+			_, err = w.Write([]byte("x"))
+			requireNoError(t, err)
+			_, err = Readable(ctx, fd).Await(ctx)
+		case rawFd(t, w):
+			_, err = Writable(ctx, fd).Await(ctx)
+		default:
+			continue
+		}
+		requireNoError(t, err)
+		return
+	}
+	t.Skip("descriptor number was not reused")
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+var errFdPollUnsupported = errors.New("async: fd readiness is only supported on linux")
+
+// Readable is only supported on linux. On other platforms the promise is
+// always rejected.
+func Readable(context.Context, int) Promise[struct{}] {
+	return Reject[struct{}](errFdPollUnsupported)
+}
+
+// Writable is only supported on linux. On other platforms the promise is
+// always rejected.
+func Writable(context.Context, int) Promise[struct{}] {
+	return Reject[struct{}](errFdPollUnsupported)
+}
@@ -0,0 +1,248 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Joined is a pair of items matched by JoinByKey.
+type Joined[L, R any] struct {
+	Left  L
+	Right R
+}
+
+// JoinConfig configures JoinByKey.
+type JoinConfig[L, R any, K comparable] struct {
+	LeftKey  func(L) K
+	RightKey func(R) K
+
+	// MaxBuffered is the number of unmatched items kept for each side. The
+	// oldest item is evicted when it is exceeded. Zero means no limit.
+	MaxBuffered int
+
+	// Window, when set, only matches items whose timestamps are at most
+	// Window apart, and evicts unmatched items that fall more than Window
+	// behind the latest timestamp seen on either side. LeftTime and
+	// RightTime MUST be set along with it.
+	Window    time.Duration
+	LeftTime  func(L) time.Time
+	RightTime func(R) time.Time
+
+	// OnUnmatchedLeft and OnUnmatchedRight, if set, are called with every
+	// item that is evicted, or still unmatched once both streams ended.
+	OnUnmatchedLeft  func(L)
+	OnUnmatchedRight func(R)
+}
+
+// JoinByKey matches items of left and right that share the same key, such as
+// requests and responses sharing an ID. Each item is matched at most once,
+// with the oldest buffered item of the other side. Both streams are read in
+// separate goroutines until they end or ctx is done. If either stream fails,
+// the joined stream fails.
+func JoinByKey[L, R any, K comparable](ctx context.Context, left Stream[L], right Stream[R], cfg JoinConfig[L, R, K]) Stream[Joined[L, R]] {
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan streamItem[Joined[L, R]])
+	lbuf := newJoinBuffer(cfg.LeftKey, cfg.LeftTime, cfg.OnUnmatchedLeft)
+	rbuf := newJoinBuffer(cfg.RightKey, cfg.RightTime, cfg.OnUnmatchedRight)
+	go func() {
+		defer cancel()
+		defer close(out)
+		send := func(it streamItem[Joined[L, R]]) bool {
+			select {
+			case out <- it:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		var latest time.Time
+		// evict drops the items that fell out of the window or the buffers.
+		evict := func(t time.Time) {
+			if cfg.Window > 0 && t.After(latest) {
+				latest = t
+			}
+			var horizon time.Time
+			if cfg.Window > 0 {
+				horizon = latest.Add(-cfg.Window)
+			}
+			lbuf.evict(cfg.MaxBuffered, horizon)
+			rbuf.evict(cfg.MaxBuffered, horizon)
+		}
+		within := func(a, b time.Time) bool {
+			d := a.Sub(b)
+			return cfg.Window <= 0 || (d <= cfg.Window && d >= -cfg.Window)
+		}
+
+		lin, rin := pumpStream(ctx, left), pumpStream(ctx, right)
+		for lin != nil || rin != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case it, ok := <-lin:
+				if !ok || errors.Is(it.err, io.EOF) {
+					lin = nil
+					continue
+				}
+				if it.err != nil {
+					send(streamItem[Joined[L, R]]{err: it.err})
+					return
+				}
+				t := lbuf.time(it.v)
+				if r, ok := rbuf.take(cfg.LeftKey(it.v), func(r R) bool { return within(t, rbuf.time(r)) }); ok {
+					if !send(streamItem[Joined[L, R]]{v: Joined[L, R]{Left: it.v, Right: r}}) {
+						return
+					}
+				} else {
+					lbuf.add(it.v)
+				}
+				evict(t)
+			case it, ok := <-rin:
+				if !ok || errors.Is(it.err, io.EOF) {
+					rin = nil
+					continue
+				}
+				if it.err != nil {
+					send(streamItem[Joined[L, R]]{err: it.err})
+					return
+				}
+				t := rbuf.time(it.v)
+				if l, ok := lbuf.take(cfg.RightKey(it.v), func(l L) bool { return within(lbuf.time(l), t) }); ok {
+					if !send(streamItem[Joined[L, R]]{v: Joined[L, R]{Left: l, Right: it.v}}) {
+						return
+					}
+				} else {
+					rbuf.add(it.v)
+				}
+				evict(t)
+			}
+		}
+		lbuf.drain()
+		rbuf.drain()
+		send(streamItem[Joined[L, R]]{err: io.EOF})
+	}()
+	return chanStream(ctx, out)
+}
+
+type joinEntry[V any] struct {
+	v    V
+	live bool
+}
+
+// joinBuffer holds the unmatched items of one side of a join, by key and in
+// arrival order.
+type joinBuffer[V any, K comparable] struct {
+	key         func(V) K
+	timestamp   func(V) time.Time
+	onUnmatched func(V)
+
+	order []*joinEntry[V]
+	byKey map[K][]*joinEntry[V]
+	live  int
+}
+
+func newJoinBuffer[V any, K comparable](key func(V) K, timestamp func(V) time.Time, onUnmatched func(V)) *joinBuffer[V, K] {
+	return &joinBuffer[V, K]{
+		key:         key,
+		timestamp:   timestamp,
+		onUnmatched: onUnmatched,
+		byKey:       map[K][]*joinEntry[V]{},
+	}
+}
+
+func (b *joinBuffer[V, K]) time(v V) time.Time {
+	if b.timestamp == nil {
+		return time.Time{}
+	}
+	return b.timestamp(v)
+}
+
+func (b *joinBuffer[V, K]) add(v V) {
+	e := &joinEntry[V]{v: v, live: true}
+	k := b.key(v)
+	b.order = append(b.order, e)
+	b.byKey[k] = append(b.byKey[k], e)
+	b.live++
+}
+
+// take removes and returns the oldest item with key k accepted by ok.
+func (b *joinBuffer[V, K]) take(k K, ok func(V) bool) (V, bool) {
+	entries := b.byKey[k]
+	for i, e := range entries {
+		if e.live && ok(e.v) {
+			e.live = false
+			b.live--
+			b.remove(k, i)
+			if len(b.order) > 2*b.live {
+				b.compact()
+			}
+			return e.v, true
+		}
+	}
+	var zerov V
+	return zerov, false
+}
+
+func (b *joinBuffer[V, K]) remove(k K, i int) {
+	entries := b.byKey[k]
+	entries = append(entries[:i], entries[i+1:]...)
+	if len(entries) == 0 {
+		delete(b.byKey, k)
+	} else {
+		b.byKey[k] = entries
+	}
+}
+
+// compact drops matched items from the arrival order, so that an old
+// unmatched item does not keep every later match in memory.
+func (b *joinBuffer[V, K]) compact() {
+	order := b.order[:0]
+	for _, e := range b.order {
+		if e.live {
+			order = append(order, e)
+		}
+	}
+	for i := len(order); i < len(b.order); i++ {
+		b.order[i] = nil
+	}
+	b.order = order
+}
+
+// evict drops the oldest items while there are more than limit of them, or
+// while they are older than horizon.
+func (b *joinBuffer[V, K]) evict(limit int, horizon time.Time) {
+	for len(b.order) > 0 {
+		e := b.order[0]
+		if e.live && (limit <= 0 || b.live <= limit) && !b.time(e.v).Before(horizon) {
+			return
+		}
+		b.order[0] = nil
+		b.order = b.order[1:]
+		if !e.live {
+			continue
+		}
+		e.live = false
+		b.live--
+		k := b.key(e.v)
+		for i, other := range b.byKey[k] {
+			if other == e {
+				b.remove(k, i)
+				break
+			}
+		}
+		if b.onUnmatched != nil {
+			b.onUnmatched(e.v)
+		}
+	}
+}
+
+// drain reports every item left as unmatched.
+func (b *joinBuffer[V, K]) drain() {
+	for _, e := range b.order {
+		if e.live && b.onUnmatched != nil {
+			b.onUnmatched(e.v)
+		}
+	}
+	b.order, b.byKey, b.live = nil, map[K][]*joinEntry[V]{}, 0
+}
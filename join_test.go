@@ -0,0 +1,74 @@
+package async
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+type joinLog struct {
+	ID string
+	At int64
+}
+
+func TestJoinByKey(t *testing.T) {
+	ctx := context.Background()
+	requests := FromSlice([]joinLog{{"a", 1}, {"b", 2}, {"c", 3}, {"a", 4}})
+	responses := FromSlice([]joinLog{{"b", 5}, {"a", 6}, {"d", 7}, {"a", 8}})
+	var unmatched []string
+	key := func(l joinLog) string { return l.ID }
+	joined, err := Collect(ctx, JoinByKey(ctx, requests, responses, JoinConfig[joinLog, joinLog, string]{
+		LeftKey:          key,
+		RightKey:         key,
+		OnUnmatchedLeft:  func(l joinLog) { unmatched = append(unmatched, "req "+l.ID) },
+		OnUnmatchedRight: func(r joinLog) { unmatched = append(unmatched, "resp "+r.ID) },
+	}))
+	requireNoError(t, err)
+	var pairs []string
+	for _, j := range joined {
+		requireEqual(t, j.Left.ID, j.Right.ID)
+		pairs = append(pairs, j.Left.ID)
+	}
+	sort.Strings(pairs)
+	sort.Strings(unmatched)
+	requireEqual(t, []string{"a", "a", "b"}, pairs)
+	requireEqual(t, []string{"req c", "resp d"}, unmatched)
+}
+
+func TestJoinByKeyWindow(t *testing.T) {
+	ctx := context.Background()
+	at := func(l joinLog) time.Time { return time.Unix(l.At, 0) }
+	key := func(l joinLog) string { return l.ID }
+	var evicted []joinLog
+	requests := FromSlice([]joinLog{{"a", 1}, {"b", 100}})
+	responses := FromSlice([]joinLog{{"b", 101}, {"a", 102}})
+	joined, err := Collect(ctx, JoinByKey(ctx, requests, responses, JoinConfig[joinLog, joinLog, string]{
+		LeftKey:          key,
+		RightKey:         key,
+		Window:           10 * time.Second,
+		LeftTime:         at,
+		RightTime:        at,
+		OnUnmatchedLeft:  func(l joinLog) { evicted = append(evicted, l) },
+		OnUnmatchedRight: func(r joinLog) { evicted = append(evicted, r) },
+	}))
+	requireNoError(t, err)
+	requireEqual(t, []Joined[joinLog, joinLog]{{Left: joinLog{"b", 100}, Right: joinLog{"b", 101}}}, joined)
+	requireEqual(t, 2, len(evicted))
+}
+
+func TestJoinBufferBounded(t *testing.T) {
+	b := newJoinBuffer[joinLog, string](func(l joinLog) string { return l.ID }, nil, nil)
+	b.add(joinLog{"lost", 0})
+	for i := 1; i <= 10000; i++ {
+		b.add(joinLog{"a", int64(i)})
+		v, ok := b.take("a", func(joinLog) bool { return true })
+		requireEqual(t, true, ok)
+		requireEqual(t, int64(i), v.At)
+		if len(b.order) > 3 {
+			t.Fatalf("buffer holds %d entries for %d live items", len(b.order), b.live)
+		}
+	}
+	requireEqual(t, 1, b.live)
+	requireEqual(t, "lost", b.order[0].v.ID)
+}
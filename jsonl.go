@@ -0,0 +1,65 @@
+package async
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DecodeJSONLines returns a stream that decodes each line of r, in the JSON
+// Lines format, into a T. Blank lines are skipped. A line that fails to
+// decode fails the stream.
+func DecodeJSONLines[T any](r io.Reader) Stream[T] {
+	br := bufio.NewReader(r)
+	line := 0
+	var failed error
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		var v T
+		if failed != nil {
+			return v, failed
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				return v, err
+			}
+			b, err := br.ReadBytes('\n')
+			if len(b) == 0 && err != nil {
+				failed = err
+				return v, err
+			}
+			line++
+			b = bytes.TrimSpace(b)
+			if len(b) == 0 {
+				continue
+			}
+			if err := json.Unmarshal(b, &v); err != nil {
+				failed = fmt.Errorf("async: jsonl line %d: %w", line, err)
+				return v, failed
+			}
+			return v, nil
+		}
+	})
+}
+
+// EncodeJSONLines writes every value of s to w as a line of JSON, until s is
+// exhausted. Outcome values are written with their index and error message,
+// so a stream returned by Outcomes can be written directly.
+func EncodeJSONLines[T any](ctx context.Context, w io.Writer, s Stream[T]) error {
+	enc := json.NewEncoder(w)
+	for {
+		v, err := s.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+}
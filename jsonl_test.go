@@ -0,0 +1,37 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type jsonlItem struct {
+	Name string `json:"name"`
+}
+
+func TestDecodeJSONLines(t *testing.T) {
+	ctx := context.Background()
+	items, err := Collect(ctx, DecodeJSONLines[jsonlItem](strings.NewReader("{\"name\":\"a\"}\n\n{\"name\":\"b\"}")))
+	requireNoError(t, err)
+	requireEqual(t, []jsonlItem{{Name: "a"}, {Name: "b"}}, items)
+
+	items, err = Collect(ctx, DecodeJSONLines[jsonlItem](strings.NewReader("{\"name\":\"a\"}\nnope\n{\"name\":\"b\"}\n")))
+	requireError(t, err)
+	requireEqual(t, "async: jsonl line 2: invalid character 'o' in literal null (expecting 'u')", err.Error())
+	requireEqual(t, []jsonlItem{{Name: "a"}}, items)
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	ctx := context.Background()
+	promises := []Promise[jsonlItem]{
+		Resolve(jsonlItem{Name: "a"}),
+		Reject[jsonlItem](errors.New("darn")),
+	}
+	var buf bytes.Buffer
+	err := EncodeJSONLines(ctx, &buf, Outcomes(ctx, FromSlice(promises), InputOrder))
+	requireNoError(t, err)
+	requireEqual(t, "{\"index\":0,\"value\":{\"name\":\"a\"}}\n{\"index\":1,\"error\":\"darn\"}\n", buf.String())
+}
@@ -0,0 +1,49 @@
+package async
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+type memoCtxKey struct{}
+
+type memoEntryKey struct {
+	typ reflect.Type
+	key any
+}
+
+type memoTable struct {
+	mu       sync.Mutex
+	promises map[memoEntryKey]any
+}
+
+// WithMemo returns a copy of ctx that carries an empty memo table for Memo.
+// It is typically installed once per request, so that everything memoized
+// during the request is shared by its code and discarded along with it.
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoCtxKey{}, &memoTable{
+		promises: map[memoEntryKey]any{},
+	})
+}
+
+// Memo returns the promise memoized under key in the table carried by ctx,
+// calling NewPromise(fn) to create it on first use. Rejections are memoized
+// like any other result. key MUST be comparable; keys are scoped by T, so the
+// same key may be used for different result types. Without a table in ctx,
+// Memo is the same as NewPromise.
+func Memo[T any](ctx context.Context, key any, fn func() (T, error)) Promise[T] {
+	table, ok := ctx.Value(memoCtxKey{}).(*memoTable)
+	if !ok {
+		return NewPromise(fn)
+	}
+	k := memoEntryKey{typ: reflect.TypeOf((*T)(nil)), key: key}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	if p, ok := table.promises[k]; ok {
+		return p.(Promise[T])
+	}
+	p := NewPromise(fn)
+	table.promises[k] = p
+	return p
+}
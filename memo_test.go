@@ -0,0 +1,42 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemo(t *testing.T) {
+	var calls int32
+	fetchUser := func(ctx context.Context, id int) Promise[string] {
+		return Memo(ctx, id, func() (string, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(time.Millisecond * 10)
+			return "user", nil
+		})
+	}
+
+	ctx := WithMemo(context.Background())
+	users, err := All(ctx, []Promise[string]{fetchUser(ctx, 1), fetchUser(ctx, 1), fetchUser(ctx, 2)})
+	requireNoError(t, err)
+	requireEqual(t, []string{"user", "user", "user"}, users)
+	requireEqual(t, int32(2), atomic.LoadInt32(&calls))
+
+	// same key, different type
+	n, err := Memo(ctx, 1, func() (int, error) { return 42, nil }).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 42, n)
+
+	// another request does not share the table
+	other := WithMemo(context.Background())
+	_, err = fetchUser(other, 1).Await(other)
+	requireNoError(t, err)
+	requireEqual(t, int32(3), atomic.LoadInt32(&calls))
+
+	// without a table nothing is shared
+	plain := context.Background()
+	_, err = All(plain, []Promise[string]{fetchUser(plain, 1), fetchUser(plain, 1)})
+	requireNoError(t, err)
+	requireEqual(t, int32(5), atomic.LoadInt32(&calls))
+}
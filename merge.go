@@ -0,0 +1,90 @@
+package async
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+)
+
+// MergeSorted merges streams that are each sorted according to less into a
+// single sorted stream. Only the next item of every stream is buffered. Items
+// that compare equal are delivered in the order of their streams. If any
+// stream fails, the merged stream fails.
+func MergeSorted[T any](less func(a, b T) bool, streams ...Stream[T]) Stream[T] {
+	h := &mergeHeap[T]{less: less}
+	started := false
+	var failed error
+	// pull reads the next item of streams[i] into the heap.
+	pull := func(ctx context.Context, i int) error {
+		v, err := streams[i].Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		heap.Push(h, mergeHead[T]{v: v, stream: i})
+		return nil
+	}
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		var zerov T
+		if failed != nil {
+			return zerov, failed
+		}
+		if !started {
+			for i := range streams {
+				if err := pull(ctx, i); err != nil {
+					failed = err
+					return zerov, err
+				}
+			}
+			started = true
+		}
+		if h.Len() == 0 {
+			failed = io.EOF
+			return zerov, failed
+		}
+		head := heap.Pop(h).(mergeHead[T])
+		// the head is delivered either way; a failed refill is reported by
+		// the next call
+		if err := pull(ctx, head.stream); err != nil {
+			failed = err
+		}
+		return head.v, nil
+	})
+}
+
+type mergeHead[T any] struct {
+	v      T
+	stream int
+}
+
+type mergeHeap[T any] struct {
+	heads []mergeHead[T]
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.heads) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.heads[i], h.heads[j]
+	if h.less(a.v, b.v) {
+		return true
+	}
+	if h.less(b.v, a.v) {
+		return false
+	}
+	return a.stream < b.stream
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.heads = append(h.heads, x.(mergeHead[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	old := h.heads
+	head := old[len(old)-1]
+	h.heads = old[:len(old)-1]
+	return head
+}
@@ -0,0 +1,39 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMergeSorted(t *testing.T) {
+	ctx := context.Background()
+	less := func(a, b int) bool { return a < b }
+	vs, err := Collect(ctx, MergeSorted(less,
+		FromSlice([]int{1, 4, 7}),
+		FromSlice([]int{2, 5, 8, 9}),
+		FromSlice([]int{}),
+		FromSlice([]int{3, 6}),
+	))
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, vs)
+
+	var failing Stream[int] = StreamFunc[int](func(context.Context) (int, error) {
+		return 0, errors.New("doh!")
+	})
+	_, err = Collect(ctx, MergeSorted(less, FromSlice([]int{1}), failing))
+	requireError(t, err)
+
+	// an item is not lost when refilling its stream fails
+	sent := false
+	var failsAfterFive Stream[int] = StreamFunc[int](func(context.Context) (int, error) {
+		if !sent {
+			sent = true
+			return 5, nil
+		}
+		return 0, errors.New("doh!")
+	})
+	vs, err = Collect(ctx, MergeSorted(less, FromSlice([]int{1}), failsAfterFive))
+	requireError(t, err)
+	requireEqual(t, []int{1, 5}, vs)
+}
@@ -0,0 +1,123 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Outcome is the settled result of a single promise out of a sequence of
+// promises.
+type Outcome[T any] struct {
+	// Index is the position of the promise in its input sequence.
+	Index int
+	Value T
+	Err   error
+}
+
+type outcomeJSON[T any] struct {
+	Index int    `json:"index"`
+	Value *T     `json:"value,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the outcome as an object with an "index" and either a
+// "value" or an "error" message.
+func (o Outcome[T]) MarshalJSON() ([]byte, error) {
+	j := outcomeJSON[T]{Index: o.Index}
+	if o.Err != nil {
+		j.Error = o.Err.Error()
+	} else {
+		j.Value = &o.Value
+	}
+	return json.Marshal(j)
+}
+
+// OutcomeOrder selects the order in which Outcomes delivers results.
+type OutcomeOrder int
+
+const (
+	// InputOrder delivers outcomes in the order of their promises, holding
+	// back results that settle before their predecessors.
+	InputOrder OutcomeOrder = iota
+
+	// CompletionOrder delivers outcomes as soon as their promises settle.
+	CompletionOrder
+)
+
+// Outcomes awaits every promise of s and delivers their results, including
+// rejections, as a stream. Unlike All, a rejected promise does not stop the
+// others from being reported. The promises are awaited concurrently until s
+// is exhausted or ctx is done.
+//
+// s is read eagerly, with one goroutine per promise, and the goroutines are
+// held until their outcomes are consumed. A consumer that stops calling Next
+// before the stream ends MUST cancel ctx to release them.
+func Outcomes[T any](ctx context.Context, s Stream[Promise[T]], order OutcomeOrder) Stream[Outcome[T]] {
+	out := make(chan Outcome[T])
+	var final error
+	go func() {
+		results := make(chan Outcome[T])
+		var srcErr error
+		go func() {
+			var wg sync.WaitGroup
+			defer func() {
+				wg.Wait()
+				close(results)
+			}()
+			for i := 0; ; i++ {
+				p, err := s.Next(ctx)
+				if err != nil {
+					srcErr = err
+					return
+				}
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					v, err := p.Await(ctx)
+					select {
+					case results <- Outcome[T]{Index: i, Value: v, Err: err}:
+					case <-ctx.Done():
+					}
+				}(i)
+			}
+		}()
+
+		send := func(o Outcome[T]) {
+			select {
+			case out <- o:
+			case <-ctx.Done():
+			}
+		}
+		held := map[int]Outcome[T]{}
+		next := 0
+		for o := range results {
+			if order == CompletionOrder {
+				send(o)
+				continue
+			}
+			held[o.Index] = o
+			for ready, ok := held[next]; ok; ready, ok = held[next] {
+				delete(held, next)
+				next++
+				send(ready)
+			}
+		}
+		final = srcErr
+		if err := ctx.Err(); err != nil {
+			final = err
+		}
+		close(out)
+	}()
+	return StreamFunc[Outcome[T]](func(nctx context.Context) (Outcome[T], error) {
+		select {
+		case <-nctx.Done():
+			return Outcome[T]{}, nctx.Err()
+		case o, ok := <-out:
+			if !ok {
+				return Outcome[T]{}, final
+			}
+			return o, nil
+		}
+	})
+}
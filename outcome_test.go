@@ -0,0 +1,74 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestOutcomes(t *testing.T) {
+	newPromises := func() []Promise[int] {
+		return []Promise[int]{
+			NewPromise(func() (int, error) {
+				time.Sleep(time.Millisecond * 100)
+				return 42, nil
+			}),
+			NewPromise(func() (int, error) {
+				time.Sleep(time.Millisecond * 50)
+				return 0, errors.New("doh!")
+			}),
+			Resolve(44),
+		}
+	}
+	ctx := context.Background()
+
+	outcomes, err := Collect(ctx, Outcomes(ctx, FromSlice(newPromises()), InputOrder))
+	requireNoError(t, err)
+	requireEqual(t, 3, len(outcomes))
+	for i, o := range outcomes {
+		requireEqual(t, i, o.Index)
+	}
+	requireEqual(t, 42, outcomes[0].Value)
+	requireEqual(t, "doh!", outcomes[1].Err.Error())
+
+	outcomes, err = Collect(ctx, Outcomes(ctx, FromSlice(newPromises()), CompletionOrder))
+	requireNoError(t, err)
+	var order []int
+	for _, o := range outcomes {
+		order = append(order, o.Index)
+	}
+	requireEqual(t, []int{2, 1, 0}, order)
+}
+
+func TestOutcomesAbandoned(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := Outcomes(ctx, FromSlice([]Promise[int]{Resolve(1), Resolve(2), Resolve(3)}), InputOrder)
+	o, err := s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 0, o.Index)
+
+	// stop consuming; cancelling ctx releases every goroutine of the stream
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err = s.Next(context.Background())
+	requireEqual(t, context.Canceled, err)
+}
+
+func TestOutcomeMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Outcome[string]{Index: 1, Value: "foo"})
+	requireNoError(t, err)
+	requireEqual(t, `{"index":1,"value":"foo"}`, string(b))
+	b, err = json.Marshal(Outcome[string]{Index: 2, Err: errors.New("darn")})
+	requireNoError(t, err)
+	requireEqual(t, `{"index":2,"error":"darn"}`, string(b))
+}
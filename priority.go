@@ -0,0 +1,167 @@
+package async
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrExecutorClosed is returned by promises submitted to an executor after it
+// was closed.
+var ErrExecutorClosed = errors.New("async: executor closed")
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx that carries priority p. Higher values
+// are more urgent.
+func WithPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority carried by ctx, or 0 if it has none.
+func PriorityFrom(ctx context.Context) int {
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+type priorityTask struct {
+	priority int
+	seq      uint64
+	index    int // position in the queue, -1 once dequeued
+	run      func()
+}
+
+type priorityQueue []*priorityTask
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *priorityQueue) Push(x any) {
+	t := x.(*priorityTask)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *priorityQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*q = old[:len(old)-1]
+	return t
+}
+
+// PriorityExecutor runs tasks on a fixed number of workers, most urgent
+// first. When a task that is still queued is awaited by a caller with a
+// higher priority, the task inherits that priority, so that urgent callers do
+// not wait behind the low priority work they depend on.
+type PriorityExecutor struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  priorityQueue
+	seq    uint64
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewPriorityExecutor starts an executor with the given number of workers.
+// A non-positive number defaults to runtime.NumCPU().
+func NewPriorityExecutor(workers int) *PriorityExecutor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	e := &PriorityExecutor{}
+	e.cond = sync.NewCond(&e.mu)
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+func (e *PriorityExecutor) work() {
+	defer e.wg.Done()
+	for {
+		e.mu.Lock()
+		for len(e.queue) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if len(e.queue) == 0 {
+			e.mu.Unlock()
+			return
+		}
+		t := heap.Pop(&e.queue).(*priorityTask)
+		e.mu.Unlock()
+		t.run()
+	}
+}
+
+// Close stops accepting tasks and waits for the queued ones to complete.
+func (e *PriorityExecutor) Close() {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.mu.Unlock()
+	e.wg.Wait()
+}
+
+// escalate raises the priority of t to p if t is still queued.
+func (e *PriorityExecutor) escalate(t *priorityTask, p int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t.index >= 0 && p > t.priority {
+		t.priority = p
+		heap.Fix(&e.queue, t.index)
+	}
+}
+
+type priorityPromise[T any] struct {
+	*syncPromise[T]
+	e    *PriorityExecutor
+	task *priorityTask
+}
+
+func (p *priorityPromise[T]) Await(ctx context.Context) (T, error) {
+	if !p.Settled() {
+		p.e.escalate(p.task, PriorityFrom(ctx))
+	}
+	return p.syncPromise.Await(ctx)
+}
+
+// SubmitPriority queues fn on e with the priority carried by ctx.
+func SubmitPriority[T any](ctx context.Context, e *PriorityExecutor, fn func() (T, error)) Promise[T] {
+	c := &syncPromise[T]{
+		done: make(chan struct{}),
+	}
+	t := &priorityTask{
+		priority: PriorityFrom(ctx),
+		run: func() {
+			c.v, c.err = fn()
+			close(c.done)
+		},
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return Reject[T](ErrExecutorClosed)
+	}
+	e.seq++
+	t.seq = e.seq
+	heap.Push(&e.queue, t)
+	e.cond.Signal()
+	return &priorityPromise[T]{syncPromise: c, e: e, task: t}
+}
@@ -0,0 +1,60 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityExecutor(t *testing.T) {
+	e := NewPriorityExecutor(1)
+	defer e.Close()
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	blocker := SubmitPriority(ctx, e, func() (int, error) {
+		close(started)
+		<-release
+		return 0, nil
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	task := func(name string) func() (string, error) {
+		return func() (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return name, nil
+		}
+	}
+	prefetch := SubmitPriority(WithPriority(ctx, 1), e, task("prefetch"))
+	SubmitPriority(WithPriority(ctx, 5), e, task("batch"))
+	urgent := SubmitPriority(WithPriority(ctx, 9), e, task("urgent"))
+
+	interactive := NewPromise(func() (string, error) {
+		return prefetch.Await(WithPriority(ctx, 7))
+	})
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	_, err := blocker.Await(ctx)
+	requireNoError(t, err)
+	_, err = All(ctx, []Promise[string]{interactive, urgent})
+	requireNoError(t, err)
+	e.Close()
+	requireEqual(t, []string{"urgent", "prefetch", "batch"}, order)
+
+	_, err = SubmitPriority(ctx, e, task("late")).Await(ctx)
+	requireEqual(t, ErrExecutorClosed, err)
+}
+
+func TestPriorityExecutorDefaultWorkers(t *testing.T) {
+	e := NewPriorityExecutor(0)
+	defer e.Close()
+	ctx := context.Background()
+	v, err := SubmitPriority(ctx, e, func() (int, error) { return 1, nil }).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+}
@@ -0,0 +1,455 @@
+package async
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// processWorkerEnv is set in the environment of every child started by a
+// ProcessPool so that ServeProcessWorker knows it should take over.
+const processWorkerEnv = "ASYNC_PROCESS_WORKER"
+
+var (
+	// ErrPoolClosed is returned by promises submitted to a ProcessPool after
+	// Close has been called.
+	ErrPoolClosed = errors.New("async: process pool closed")
+
+	// ErrWorkerCrashed is returned when the worker process running a task
+	// exits before delivering a result.
+	ErrWorkerCrashed = errors.New("async: worker process crashed")
+
+	// ErrWorkerMemory is returned when the worker process running a task
+	// grows past ProcessPoolConfig.MaxMemory and is killed.
+	ErrWorkerMemory = errors.New("async: worker process exceeded memory limit")
+
+	// ErrUnknownHandler is returned when a task names a handler that was not
+	// registered with RegisterProcessHandler.
+	ErrUnknownHandler = errors.New("async: unknown process handler")
+)
+
+// errNotDelivered marks a task that could not be sent to its worker, and so
+// never ran.
+var errNotDelivered = errors.New("task not delivered")
+
+type processHandler func(payload []byte) ([]byte, error)
+
+var (
+	processHandlersMu sync.RWMutex
+	processHandlers   = map[string]processHandler{}
+)
+
+// RegisterProcessHandler makes fn available to ProcessPool workers under
+// name. Handlers MUST be registered identically in the parent and in the
+// worker binary, typically from a package level var or init function, since
+// the worker is a separate process.
+func RegisterProcessHandler[In, Out any](name string, fn func(In) (Out, error)) {
+	processHandlersMu.Lock()
+	defer processHandlersMu.Unlock()
+	processHandlers[name] = func(payload []byte) ([]byte, error) {
+		var in In
+		if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&in); err != nil {
+			return nil, err
+		}
+		out, err := fn(in)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(&out); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+func lookupProcessHandler(name string) (processHandler, bool) {
+	processHandlersMu.RLock()
+	defer processHandlersMu.RUnlock()
+	h, ok := processHandlers[name]
+	return h, ok
+}
+
+type processRequest struct {
+	Name    string
+	Payload []byte
+}
+
+type processResponse struct {
+	Payload []byte
+	Err     string
+	Failed  bool
+	Unknown bool // the handler was not registered in the worker
+}
+
+// ServeProcessWorker turns the current process into a ProcessPool worker if
+// it was started by one, and reports false otherwise. It should be called at
+// the very beginning of main (or TestMain); when it takes over, it serves
+// tasks from stdin until the parent closes it and then exits the process.
+// Worker processes MUST NOT write anything else to stdout.
+func ServeProcessWorker() bool {
+	if os.Getenv(processWorkerEnv) == "" {
+		return false
+	}
+	err := serveProcessWorker(os.Stdin, os.Stdout)
+	if err != nil && !errors.Is(err, io.EOF) {
+		fmt.Fprintln(os.Stderr, "async: process worker:", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+	return true
+}
+
+func serveProcessWorker(r io.Reader, w io.Writer) error {
+	dec := gob.NewDecoder(r)
+	enc := gob.NewEncoder(w)
+	for {
+		var req processRequest
+		if err := dec.Decode(&req); err != nil {
+			return err
+		}
+		payload, err := runProcessHandler(req)
+		resp := processResponse{Payload: payload}
+		if err != nil {
+			resp.Err, resp.Failed = err.Error(), true
+			resp.Unknown = errors.Is(err, ErrUnknownHandler)
+		}
+		if err := enc.Encode(&resp); err != nil {
+			return err
+		}
+	}
+}
+
+func runProcessHandler(req processRequest) (payload []byte, err error) {
+	h, ok := lookupProcessHandler(req.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownHandler, req.Name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("async: process handler %q panicked: %v", req.Name, r)
+		}
+	}()
+	return h(req.Payload)
+}
+
+// ProcessPoolConfig configures a ProcessPool.
+type ProcessPoolConfig struct {
+	// Workers is the number of child processes. Defaults to runtime.NumCPU().
+	Workers int
+
+	// Command builds the command used to start a worker. The command's
+	// program MUST call ServeProcessWorker. Defaults to re-executing the
+	// current binary.
+	Command func() *exec.Cmd
+
+	// MaxMemory is the resident set size, in bytes, above which a worker is
+	// killed and restarted. Zero disables the check. Only enforced on
+	// platforms where the resident set size of a child can be read.
+	MaxMemory uint64
+
+	// MemoryCheckInterval controls how often a busy worker's memory is
+	// sampled. Defaults to 100ms.
+	MemoryCheckInterval time.Duration
+}
+
+// ProcessPool runs registered handlers in a pool of child processes, so that
+// a crash inside a handler only rejects the task it was running instead of
+// taking down the whole program.
+type ProcessPool struct {
+	cfg     ProcessPoolConfig
+	pending chan struct{} // signals tasks waiting in queue
+	closed  chan struct{}
+	wg      sync.WaitGroup
+
+	mu    sync.Mutex
+	queue []*processTask
+}
+
+type processTask struct {
+	req    processRequest
+	settle func(payload []byte, err error)
+}
+
+// NewProcessPool starts a pool of worker processes. Workers are started
+// lazily, when they receive their first task.
+func NewProcessPool(cfg ProcessPoolConfig) *ProcessPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Command == nil {
+		cfg.Command = defaultProcessCommand
+	}
+	if cfg.MemoryCheckInterval <= 0 {
+		cfg.MemoryCheckInterval = 100 * time.Millisecond
+	}
+	p := &ProcessPool{
+		cfg:     cfg,
+		pending: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func defaultProcessCommand() *exec.Cmd {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	return exec.Command(exe)
+}
+
+// Close stops accepting new tasks, waits for running tasks to finish and
+// shuts down all worker processes. Tasks still waiting for a worker are
+// rejected with ErrPoolClosed.
+func (p *ProcessPool) Close() error {
+	p.mu.Lock()
+	select {
+	case <-p.closed:
+	default:
+		close(p.closed)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	queue := p.queue
+	p.queue = nil
+	p.mu.Unlock()
+	for _, t := range queue {
+		t.settle(nil, ErrPoolClosed)
+	}
+	return nil
+}
+
+// RunInProcess submits a task to the named handler of the pool. The input and
+// output are transferred with encoding/gob. It never blocks: tasks wait in an
+// unbounded queue until a worker is free.
+func RunInProcess[In, Out any](p *ProcessPool, name string, in In) Promise[Out] {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&in); err != nil {
+		return Reject[Out](err)
+	}
+	c := &syncPromise[Out]{
+		done: make(chan struct{}),
+	}
+	t := &processTask{
+		req: processRequest{Name: name, Payload: buf.Bytes()},
+		settle: func(payload []byte, err error) {
+			if err == nil {
+				err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&c.v)
+			}
+			c.err = err
+			close(c.done)
+		},
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-p.closed:
+		return Reject[Out](ErrPoolClosed)
+	default:
+	}
+	p.queue = append(p.queue, t)
+	p.signal()
+	return c
+}
+
+// signal wakes up a worker. Must be called with p.mu held.
+func (p *ProcessPool) signal() {
+	select {
+	case p.pending <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest queued task, if any.
+func (p *ProcessPool) next() *processTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) == 0 {
+		return nil
+	}
+	t := p.queue[0]
+	p.queue[0] = nil
+	p.queue = p.queue[1:]
+	if len(p.queue) > 0 {
+		p.signal()
+	}
+	return t
+}
+
+func (p *ProcessPool) work() {
+	defer p.wg.Done()
+	var w *processWorker
+	defer func() {
+		if w != nil {
+			w.stop()
+		}
+	}()
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-p.pending:
+		}
+		select {
+		case <-p.closed:
+			// queued tasks are rejected by Close
+			return
+		default:
+		}
+		if t := p.next(); t != nil {
+			t.settle(p.runTask(&w, t.req))
+		}
+	}
+}
+
+// runTask runs req on *w, starting or replacing the worker as needed. A
+// worker that died while idle is replaced and the task sent again, since it
+// never ran.
+func (p *ProcessPool) runTask(w **processWorker, req processRequest) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if *w == nil {
+			var err error
+			if *w, err = startProcessWorker(p.cfg.Command()); err != nil {
+				return nil, err
+			}
+		}
+		payload, healthy, err := (*w).run(req, p.cfg.MaxMemory, p.cfg.MemoryCheckInterval)
+		if !healthy {
+			(*w).kill()
+			*w = nil
+			if errors.Is(err, errNotDelivered) {
+				if attempt == 0 {
+					continue
+				}
+				err = fmt.Errorf("%w: %v", ErrWorkerCrashed, err)
+			}
+		} else if p.cfg.MaxMemory > 0 {
+			if rss, known := processRSS((*w).cmd.Process.Pid); known && rss > p.cfg.MaxMemory {
+				(*w).kill()
+				*w = nil
+			}
+		}
+		return payload, err
+	}
+}
+
+type processWorker struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	enc    *gob.Encoder
+	dec    *gob.Decoder
+	exited chan struct{}
+	err    error
+}
+
+func startProcessWorker(cmd *exec.Cmd) (*processWorker, error) {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, processWorkerEnv+"=1")
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &processWorker{
+		cmd:    cmd,
+		stdin:  stdin,
+		enc:    gob.NewEncoder(stdin),
+		dec:    gob.NewDecoder(stdout),
+		exited: make(chan struct{}),
+	}, nil
+}
+
+// run sends a single request to the worker and waits for its response.
+// healthy is false when the worker can no longer be used and must be replaced.
+func (w *processWorker) run(req processRequest, maxMemory uint64, interval time.Duration) (payload []byte, healthy bool, err error) {
+	if err := w.enc.Encode(&req); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", errNotDelivered, err)
+	}
+	type result struct {
+		resp processResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = w.dec.Decode(&r.resp)
+		done <- r
+	}()
+
+	var tick <-chan time.Time
+	if maxMemory > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case r := <-done:
+			if r.err != nil {
+				return nil, false, fmt.Errorf("%w: %v", ErrWorkerCrashed, w.kill())
+			}
+			if r.resp.Unknown {
+				return nil, true, fmt.Errorf("%w: %q", ErrUnknownHandler, req.Name)
+			}
+			if r.resp.Failed {
+				return nil, true, errors.New(r.resp.Err)
+			}
+			return r.resp.Payload, true, nil
+		case <-tick:
+			if rss, known := processRSS(w.cmd.Process.Pid); known && rss > maxMemory {
+				w.kill()
+				<-done
+				return nil, false, ErrWorkerMemory
+			}
+		}
+	}
+}
+
+// kill terminates the worker and reports how it exited.
+func (w *processWorker) kill() error {
+	select {
+	case <-w.exited:
+	default:
+		_ = w.cmd.Process.Kill()
+		w.err = w.cmd.Wait()
+		close(w.exited)
+	}
+	return w.err
+}
+
+// stop asks the worker to exit by closing its stdin.
+func (w *processWorker) stop() {
+	select {
+	case <-w.exited:
+	default:
+		_ = w.stdin.Close()
+		w.err = w.cmd.Wait()
+		close(w.exited)
+	}
+}
@@ -0,0 +1,25 @@
+package async
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processRSS reports the resident set size of the process with the given pid.
+func processRSS(pid int) (uint64, bool) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * uint64(os.Getpagesize()), true
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package async
+
+// processRSS is not supported on this platform, so memory limits are not
+// enforced.
+func processRSS(int) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,116 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	if ServeProcessWorker() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func init() {
+	RegisterProcessHandler("upper", func(s string) (string, error) {
+		return strings.ToUpper(s), nil
+	})
+	RegisterProcessHandler("fail", func(s string) (string, error) {
+		return "", errors.New(s)
+	})
+	RegisterProcessHandler("crash", func(code int) (int, error) {
+		os.Exit(code)
+		return 0, nil
+	})
+	RegisterProcessHandler("pid", func(struct{}) (int, error) {
+		return os.Getpid(), nil
+	})
+	RegisterProcessHandler("sleep", func(d time.Duration) (int, error) {
+		time.Sleep(d)
+		return 0, nil
+	})
+}
+
+func TestProcessPool(t *testing.T) {
+	pool := NewProcessPool(ProcessPoolConfig{Workers: 2})
+	defer pool.Close()
+	ctx := context.Background()
+
+	v, err := RunInProcess[string, string](pool, "upper", "foo").Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "FOO", v)
+
+	_, err = RunInProcess[string, string](pool, "fail", "darn").Await(ctx)
+	requireError(t, err)
+	requireEqual(t, "darn", err.Error())
+
+	_, err = RunInProcess[string, string](pool, "missing", "").Await(ctx)
+	requireEqual(t, true, errors.Is(err, ErrUnknownHandler))
+
+	_, err = RunInProcess[int, int](pool, "crash", 3).Await(ctx)
+	requireEqual(t, true, errors.Is(err, ErrWorkerCrashed))
+
+	// the crashed worker is replaced
+	v, err = RunInProcess[string, string](pool, "upper", "bar").Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "BAR", v)
+
+	requireNoError(t, pool.Close())
+	_, err = RunInProcess[string, string](pool, "upper", "baz").Await(ctx)
+	requireEqual(t, ErrPoolClosed, err)
+}
+
+func TestProcessPoolIdleWorkerKilled(t *testing.T) {
+	pool := NewProcessPool(ProcessPoolConfig{Workers: 1})
+	defer pool.Close()
+	ctx := context.Background()
+
+	pid, err := RunInProcess[struct{}, int](pool, "pid", struct{}{}).Await(ctx)
+	requireNoError(t, err)
+	proc, err := os.FindProcess(pid)
+	requireNoError(t, err)
+	requireNoError(t, proc.Kill())
+	time.Sleep(time.Millisecond * 50)
+
+	// the task never reached the dead worker, so it runs on its replacement
+	v, err := RunInProcess[string, string](pool, "upper", "foo").Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "FOO", v)
+}
+
+func TestProcessPoolQueue(t *testing.T) {
+	pool := NewProcessPool(ProcessPoolConfig{Workers: 1})
+	ctx := context.Background()
+
+	// submitting never blocks, however busy the workers are
+	promises := make([]Promise[int], 4)
+	for i := range promises {
+		promises[i] = RunInProcess[time.Duration, int](pool, "sleep", time.Millisecond*50)
+	}
+	requireEqual(t, false, promises[0].Settled())
+	_, err := promises[0].Await(ctx)
+	requireNoError(t, err)
+	requireNoError(t, pool.Close())
+	_, err = promises[len(promises)-1].Await(ctx)
+	requireEqual(t, ErrPoolClosed, err)
+}
+
+func TestProcessPoolMemory(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory limits are only enforced on linux")
+	}
+	pool := NewProcessPool(ProcessPoolConfig{
+		Workers:             1,
+		MaxMemory:           1,
+		MemoryCheckInterval: time.Millisecond * 10,
+	})
+	defer pool.Close()
+	_, err := RunInProcess[time.Duration, int](pool, "sleep", time.Second).Await(context.Background())
+	requireEqual(t, ErrWorkerMemory, err)
+}
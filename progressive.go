@@ -0,0 +1,172 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAlreadySettled is returned when settling a Progressive that already
+// delivered its final value.
+var ErrAlreadySettled = errors.New("async: promise already settled")
+
+// Progressive is a promise that may settle twice: first with a provisional
+// value, such as a stale cached result, and later with its final value, as
+// for stale-while-revalidate. The zero value is not usable; use
+// NewProgressive.
+type Progressive[T any] struct {
+	first chan struct{}
+	final chan struct{}
+
+	mu          sync.Mutex
+	v           T
+	err         error
+	provisional bool
+	subscribers []*progressiveSub[T]
+}
+
+// progressiveSub serializes the deliveries to one subscriber, so that it
+// never sees the provisional value after the final one.
+type progressiveSub[T any] struct {
+	mu    sync.Mutex
+	fn    func(v T, err error, final bool)
+	final bool
+}
+
+func (s *progressiveSub[T]) deliver(v T, err error, final bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.final {
+		return
+	}
+	s.final = final
+	s.fn(v, err, final)
+}
+
+// NewProgressive creates a Progressive that has not settled yet.
+func NewProgressive[T any]() *Progressive[T] {
+	return &Progressive[T]{
+		first: make(chan struct{}),
+		final: make(chan struct{}),
+	}
+}
+
+// Provide settles p with a provisional value. It is ignored, and reports
+// false, once a provisional or final value was delivered.
+func (p *Progressive[T]) Provide(v T) bool {
+	p.mu.Lock()
+	if p.isClosed(p.first) {
+		p.mu.Unlock()
+		return false
+	}
+	p.v, p.provisional = v, true
+	close(p.first)
+	subscribers := p.subscribers
+	p.mu.Unlock()
+	for _, sub := range subscribers {
+		sub.deliver(v, nil, false)
+	}
+	return true
+}
+
+// Settle delivers the final value or error of p. It returns
+// ErrAlreadySettled if p already has a final value.
+func (p *Progressive[T]) Settle(v T, err error) error {
+	p.mu.Lock()
+	if p.isClosed(p.final) {
+		p.mu.Unlock()
+		return ErrAlreadySettled
+	}
+	p.v, p.err, p.provisional = v, err, false
+	if !p.isClosed(p.first) {
+		close(p.first)
+	}
+	close(p.final)
+	subscribers := p.subscribers
+	p.subscribers = nil
+	p.mu.Unlock()
+	for _, sub := range subscribers {
+		sub.deliver(v, err, true)
+	}
+	return nil
+}
+
+func (p *Progressive[T]) isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// AwaitFirst blocks until p has either a provisional or a final value, and
+// reports which one it delivered.
+func (p *Progressive[T]) AwaitFirst(ctx context.Context) (v T, final bool, err error) {
+	select {
+	case <-ctx.Done():
+		return v, false, ctx.Err()
+	case <-p.first:
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.v, !p.provisional, p.err
+}
+
+// AwaitFinal blocks until p has its final value.
+func (p *Progressive[T]) AwaitFinal(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zerov T
+		return zerov, ctx.Err()
+	case <-p.final:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.v, p.err
+	}
+}
+
+// Await is AwaitFinal, so that a Progressive can be used as a Promise.
+func (p *Progressive[T]) Await(ctx context.Context) (T, error) {
+	return p.AwaitFinal(ctx)
+}
+
+// Settled reports whether p has its final value.
+func (p *Progressive[T]) Settled() bool {
+	return p.isClosed(p.final)
+}
+
+// Subscribe calls fn with the provisional value and with the final result,
+// as they are delivered. Values delivered before Subscribe was called are
+// replayed right away: the final result if there is one, otherwise the
+// provisional value if there is one. fn is called from the goroutine that
+// delivers the value, and must not block.
+func (p *Progressive[T]) Subscribe(fn func(v T, err error, final bool)) {
+	sub := &progressiveSub[T]{fn: fn}
+	p.mu.Lock()
+	v, err := p.v, p.err
+	if p.isClosed(p.final) {
+		p.mu.Unlock()
+		sub.deliver(v, err, true)
+		return
+	}
+	p.subscribers = append(p.subscribers, sub)
+	provisional := p.isClosed(p.first)
+	p.mu.Unlock()
+	if provisional {
+		sub.deliver(v, nil, false)
+	}
+}
+
+// StaleWhileRevalidate returns a Progressive that is provisionally settled
+// with stale, if ok, and finally settled with the result of fresh.
+func StaleWhileRevalidate[T any](stale T, ok bool, fresh func() (T, error)) *Progressive[T] {
+	p := NewProgressive[T]()
+	if ok {
+		p.Provide(stale)
+	}
+	go func() {
+		_ = p.Settle(fresh())
+	}()
+	return p
+}
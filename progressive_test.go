@@ -0,0 +1,69 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressive(t *testing.T) {
+	ctx := context.Background()
+	p := NewProgressive[string]()
+	requireEqual(t, true, p.Provide("stale"))
+	requireEqual(t, false, p.Provide("staler"))
+
+	v, final, err := p.AwaitFirst(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "stale", v)
+	requireEqual(t, false, final)
+	requireEqual(t, false, p.Settled())
+
+	var mu sync.Mutex
+	var seen []string
+	p.Subscribe(func(v string, err error, final bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, v)
+	})
+
+	requireNoError(t, p.Settle("fresh", nil))
+	requireEqual(t, ErrAlreadySettled, p.Settle("fresher", nil))
+	v, err = p.AwaitFinal(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "fresh", v)
+	v, final, err = p.AwaitFirst(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "fresh", v)
+	requireEqual(t, true, final)
+	requireEqual(t, []string{"stale", "fresh"}, seen)
+
+	// a Progressive is a Promise of its final value
+	var promise Promise[string] = p
+	v, err = promise.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "fresh", v)
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	p := StaleWhileRevalidate("cached", true, func() (string, error) {
+		time.Sleep(time.Millisecond * 50)
+		return "", errors.New("darn")
+	})
+	v, final, err := p.AwaitFirst(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "cached", v)
+	requireEqual(t, false, final)
+	_, err = p.AwaitFinal(ctx)
+	requireError(t, err)
+
+	p = StaleWhileRevalidate("", false, func() (string, error) {
+		return "fresh", nil
+	})
+	v, final, err = p.AwaitFirst(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "fresh", v)
+	requireEqual(t, true, final)
+}
@@ -0,0 +1,195 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateUnreachable is returned by KeyedRateLimiter.Wait when the limit of
+// the key can never grant another token, because its rate is zero and its
+// bucket is empty.
+var ErrRateUnreachable = errors.New("async: rate limit can never be satisfied")
+
+// Limit describes a token bucket.
+type Limit struct {
+	// Rate is the number of tokens added to the bucket per second.
+	Rate float64
+
+	// Burst is the maximum number of tokens the bucket holds.
+	Burst int
+}
+
+type tokenBucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+		b.last = now
+	}
+}
+
+func (b *tokenBucket) full() bool {
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+// KeyedRateLimiter maintains an independent token bucket for every key, for
+// instance to respect rate limits that an external API applies per account.
+// Buckets are created on first use and evicted once they have been idle, and
+// so full, for the configured duration.
+type KeyedRateLimiter[K comparable] struct {
+	def  Limit
+	idle time.Duration
+	now  func() time.Time
+
+	mu        sync.Mutex
+	limits    map[K]Limit
+	buckets   map[K]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewKeyedRateLimiter creates a limiter that applies def to every key without
+// a limit of its own, and evicts buckets that have been idle for idle.
+func NewKeyedRateLimiter[K comparable](def Limit, idle time.Duration) *KeyedRateLimiter[K] {
+	return &KeyedRateLimiter[K]{
+		def:     def,
+		idle:    idle,
+		now:     time.Now,
+		limits:  map[K]Limit{},
+		buckets: map[K]*tokenBucket{},
+	}
+}
+
+// SetLimit changes the limit applied to key. Tokens already accumulated by
+// the key are kept, up to the new burst.
+func (l *KeyedRateLimiter[K]) SetLimit(key K, lim Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[key] = lim
+	l.apply(key, lim)
+}
+
+// ResetLimit makes key use the default limit again.
+func (l *KeyedRateLimiter[K]) ResetLimit(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.limits, key)
+	l.apply(key, l.def)
+}
+
+// apply changes the limit of the bucket of key, if there is one. Must be
+// called with l.mu held.
+func (l *KeyedRateLimiter[K]) apply(key K, lim Limit) {
+	if b, ok := l.buckets[key]; ok {
+		b.advance(l.now())
+		b.limit = lim
+		b.tokens = math.Min(b.tokens, float64(lim.Burst))
+	}
+}
+
+// bucket returns the bucket of key, advanced to now. Must be called with
+// l.mu held.
+func (l *KeyedRateLimiter[K]) bucket(key K, now time.Time) *tokenBucket {
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		lim, ok := l.limits[key]
+		if !ok {
+			lim = l.def
+		}
+		b = &tokenBucket{limit: lim, tokens: float64(lim.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.advance(now)
+	return b
+}
+
+// sweep evicts idle buckets. Must be called with l.mu held.
+func (l *KeyedRateLimiter[K]) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idle {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) < l.idle {
+			continue
+		}
+		if b.advance(now); b.full() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Allow takes a token for key if one is available right now.
+func (l *KeyedRateLimiter[K]) Allow(key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key, l.now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait blocks until a token for key is available and takes it. If ctx is done
+// first, the reserved token is handed back and ctx.Err() is returned.
+func (l *KeyedRateLimiter[K]) Wait(ctx context.Context, key K) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	now := l.now()
+	b := l.bucket(key, now)
+	if b.tokens < 1 && b.limit.Rate <= 0 {
+		l.mu.Unlock()
+		return ErrRateUnreachable
+	}
+	b.tokens--
+	wait := time.Duration(0)
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if wait == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		b := l.bucket(key, l.now())
+		b.tokens = math.Min(b.tokens+1, float64(b.limit.Burst))
+		return ctx.Err()
+	}
+}
+
+// Len reports the number of buckets currently held.
+func (l *KeyedRateLimiter[K]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// NewRateLimitedPromise is like NewPromise, but fn only starts once l grants
+// a token for key. The promise is rejected if ctx is done before then.
+func NewRateLimitedPromise[K comparable, T any](ctx context.Context, l *KeyedRateLimiter[K], key K, fn func() (T, error)) Promise[T] {
+	return NewPromise(func() (T, error) {
+		if err := l.Wait(ctx, key); err != nil {
+			var zerov T
+			return zerov, err
+		}
+		return fn()
+	})
+}
@@ -0,0 +1,83 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewKeyedRateLimiter[string](Limit{Rate: 1, Burst: 2}, time.Minute)
+	l.now = func() time.Time { return now }
+
+	requireEqual(t, true, l.Allow("a"))
+	requireEqual(t, true, l.Allow("a"))
+	requireEqual(t, false, l.Allow("a"))
+	requireEqual(t, true, l.Allow("b")) // keys don't share a bucket
+
+	now = now.Add(time.Second)
+	requireEqual(t, true, l.Allow("a"))
+	requireEqual(t, false, l.Allow("a"))
+
+	l.SetLimit("a", Limit{Rate: 10, Burst: 5})
+	now = now.Add(time.Second)
+	for i := 0; i < 5; i++ {
+		requireEqual(t, true, l.Allow("a"))
+	}
+	requireEqual(t, false, l.Allow("a"))
+
+	requireEqual(t, 2, l.Len())
+	now = now.Add(time.Minute)
+	requireEqual(t, true, l.Allow("c"))
+	requireEqual(t, 1, l.Len())
+}
+
+func TestKeyedRateLimiterWait(t *testing.T) {
+	l := NewKeyedRateLimiter[string](Limit{Rate: 20, Burst: 1}, time.Minute)
+	ctx := context.Background()
+	requireNoError(t, l.Wait(ctx, "a"))
+	start := time.Now()
+	requireNoError(t, l.Wait(ctx, "a"))
+	if elapsed := time.Since(start); elapsed < time.Millisecond*40 {
+		t.Fatalf("expected to wait for a token, waited %s", elapsed)
+	}
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	requireEqual(t, context.DeadlineExceeded, l.Wait(ctxlowtimeout, "a"))
+
+	l.SetLimit("never", Limit{})
+	requireEqual(t, ErrRateUnreachable, l.Wait(ctx, "never"))
+
+	// a bucket that is never refilled only grants its burst
+	l.SetLimit("once", Limit{Burst: 2})
+	requireNoError(t, l.Wait(ctx, "once"))
+	requireNoError(t, l.Wait(ctx, "once"))
+	requireEqual(t, ErrRateUnreachable, l.Wait(ctx, "once"))
+
+	_, err := NewRateLimitedPromise(ctx, l, "b", func() (int, error) {
+		return 42, nil
+	}).Await(ctx)
+	requireNoError(t, err)
+}
+
+func TestKeyedRateLimiterWaitCanceledRefill(t *testing.T) {
+	l := NewKeyedRateLimiter[string](Limit{Rate: 1, Burst: 1}, time.Hour)
+	base := time.Now()
+	var offset int64
+	l.now = func() time.Time { return base.Add(time.Duration(atomic.LoadInt64(&offset))) }
+	requireEqual(t, true, l.Allow("a"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		// the bucket refills while the token is reserved
+		atomic.StoreInt64(&offset, int64(10*time.Second))
+		cancel()
+	}()
+	requireEqual(t, context.Canceled, l.Wait(ctx, "a"))
+	requireEqual(t, true, l.Allow("a"))
+	requireEqual(t, false, l.Allow("a"))
+}
@@ -0,0 +1,103 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrReleased is returned by a ReleasablePromise once its value was released.
+var ErrReleased = errors.New("async: promise value released")
+
+// ReleaseConfig controls when a ReleasablePromise drops its value.
+type ReleaseConfig struct {
+	// Consumers is the number of Await calls that will deliver the result;
+	// once they all have, the value is released. Zero disables this.
+	Consumers int
+
+	// Retain is how long the result is kept after the promise settles. Zero
+	// disables this.
+	Retain time.Duration
+}
+
+// ReleasablePromise is a promise that drops its value once it is no longer
+// needed, so that a promise kept in a long-lived cache or registry does not
+// pin a large result in memory. Await calls made after the release return
+// ErrReleased.
+type ReleasablePromise[T any] struct {
+	done chan struct{}
+
+	mu        sync.Mutex
+	v         T
+	err       error
+	released  bool
+	remaining int
+}
+
+// NewReleasablePromise is like NewPromise, but the result is released
+// according to cfg.
+func NewReleasablePromise[T any](cfg ReleaseConfig, fn func() (T, error)) *ReleasablePromise[T] {
+	r := &ReleasablePromise[T]{
+		done:      make(chan struct{}),
+		remaining: cfg.Consumers,
+	}
+	go func() {
+		v, err := fn()
+		r.mu.Lock()
+		if !r.released {
+			r.v, r.err = v, err
+		}
+		r.mu.Unlock()
+		close(r.done)
+		if cfg.Retain > 0 {
+			time.AfterFunc(cfg.Retain, r.Release)
+		}
+	}()
+	return r
+}
+
+// Await delivers the result unless it has already been released.
+func (r *ReleasablePromise[T]) Await(ctx context.Context) (T, error) {
+	var zerov T
+	select {
+	case <-ctx.Done():
+		return zerov, ctx.Err()
+	case <-r.done:
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.released {
+		return zerov, ErrReleased
+	}
+	v, err := r.v, r.err
+	if r.remaining > 0 {
+		r.remaining--
+		if r.remaining == 0 {
+			r.release()
+		}
+	}
+	return v, err
+}
+
+func (r *ReleasablePromise[T]) Settled() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release drops the result right away. Calling Release before the promise
+// settles releases the result as soon as it is delivered.
+func (r *ReleasablePromise[T]) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.release()
+}
+
+func (r *ReleasablePromise[T]) release() {
+	var zerov T
+	r.v, r.err, r.released = zerov, nil, true
+}
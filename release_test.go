@@ -0,0 +1,40 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReleasablePromise(t *testing.T) {
+	ctx := context.Background()
+	promise := NewReleasablePromise(ReleaseConfig{Consumers: 2}, func() ([]byte, error) {
+		return make([]byte, 1<<20), nil
+	})
+	for i := 0; i < 2; i++ {
+		v, err := promise.Await(ctx)
+		requireNoError(t, err)
+		requireEqual(t, 1<<20, len(v))
+	}
+	requireEqual(t, true, promise.Settled())
+	_, err := promise.Await(ctx)
+	requireEqual(t, ErrReleased, err)
+
+	promise = NewReleasablePromise(ReleaseConfig{Retain: time.Millisecond * 50}, func() ([]byte, error) {
+		return []byte("foo"), nil
+	})
+	v, err := promise.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "foo", string(v))
+	time.Sleep(time.Millisecond * 100)
+	_, err = promise.Await(ctx)
+	requireEqual(t, ErrReleased, err)
+
+	promise = NewReleasablePromise(ReleaseConfig{}, func() ([]byte, error) {
+		time.Sleep(time.Millisecond * 50)
+		return []byte("foo"), nil
+	})
+	promise.Release()
+	_, err = promise.Await(ctx)
+	requireEqual(t, ErrReleased, err)
+}
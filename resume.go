@@ -0,0 +1,124 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ResumeConfig configures a ResumableStream reading items of type T at
+// offsets, or cursors, of type O.
+type ResumeConfig[T, O any] struct {
+	// Open opens the underlying stream so that its first item is the one
+	// following offset.
+	Open func(ctx context.Context, offset O) (Stream[T], error)
+
+	// Offset returns the offset to resume from once v has been consumed.
+	Offset func(v T) O
+
+	// Retryable reports whether an error of the underlying stream, or of
+	// Open, is transient. Defaults to treating every error as transient.
+	Retryable func(error) bool
+
+	// Backoff returns how long to wait before the given attempt at reopening,
+	// counted from 1. Defaults to an exponential backoff from 100ms to 10s.
+	Backoff func(attempt int) time.Duration
+
+	// MaxAttempts is the number of consecutive failed attempts after which the
+	// stream fails. Zero means no limit.
+	MaxAttempts int
+
+	// Clock is used to wait between attempts. Defaults to SystemClock.
+	Clock Clock
+}
+
+// ResumableStream wraps a stream that can be reopened at an offset, so that
+// a transient failure, such as a dropped connection while tailing a file or
+// paginating a change feed, reopens the stream where its consumer left off
+// instead of interrupting it.
+type ResumableStream[T, O any] struct {
+	cfg      ResumeConfig[T, O]
+	offset   O
+	cur      Stream[T]
+	attempts int
+	failed   error
+}
+
+// Resumable creates a ResumableStream that starts reading at offset.
+func Resumable[T, O any](offset O, cfg ResumeConfig[T, O]) *ResumableStream[T, O] {
+	if cfg.Retryable == nil {
+		cfg.Retryable = func(error) bool { return true }
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+	return &ResumableStream[T, O]{cfg: cfg, offset: offset}
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	d := 100 * time.Millisecond
+	for i := 1; i < attempt && d < 10*time.Second; i++ {
+		d *= 2
+	}
+	if d > 10*time.Second {
+		d = 10 * time.Second
+	}
+	return d
+}
+
+// Offset reports the offset after the last item delivered by Next.
+func (r *ResumableStream[T, O]) Offset() O {
+	return r.offset
+}
+
+// Next delivers the next item, reopening the underlying stream as needed.
+func (r *ResumableStream[T, O]) Next(ctx context.Context) (T, error) {
+	var zerov T
+	for r.failed == nil {
+		var err error
+		if r.cur == nil {
+			r.cur, err = r.cfg.Open(ctx, r.offset)
+		}
+		if err == nil {
+			var v T
+			if v, err = r.cur.Next(ctx); err == nil {
+				r.offset = r.cfg.Offset(v)
+				r.attempts = 0
+				return v, nil
+			}
+			if errors.Is(err, io.EOF) {
+				r.failed = err
+				break
+			}
+			r.close()
+		}
+		if ctx.Err() != nil {
+			return zerov, ctx.Err()
+		}
+		r.attempts++
+		if !r.cfg.Retryable(err) || (r.cfg.MaxAttempts > 0 && r.attempts >= r.cfg.MaxAttempts) {
+			r.failed = err
+			break
+		}
+		t := r.cfg.Clock.NewTimer(r.cfg.Backoff(r.attempts))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return zerov, ctx.Err()
+		case <-t.C():
+		}
+	}
+	return zerov, r.failed
+}
+
+// close drops the underlying stream, closing it if it is an io.Closer.
+func (r *ResumableStream[T, O]) close() {
+	if c, ok := r.cur.(io.Closer); ok {
+		_ = c.Close()
+	}
+	r.cur = nil
+}
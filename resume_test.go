@@ -0,0 +1,80 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestResumable(t *testing.T) {
+	feed := []int{1, 2, 3, 4, 5}
+	transient := errors.New("connection reset")
+	var opens []int
+	open := func(ctx context.Context, offset int) (Stream[int], error) {
+		opens = append(opens, offset)
+		if len(opens) == 2 {
+			return nil, transient
+		}
+		i, served := offset, 0
+		return StreamFunc[int](func(context.Context) (int, error) {
+			if i == len(feed) {
+				return 0, io.EOF
+			}
+			// every connection breaks after two items
+			if served == 2 {
+				return 0, transient
+			}
+			i++
+			served++
+			return feed[i-1], nil
+		}), nil
+	}
+	s := Resumable(0, ResumeConfig[int, int]{
+		Open:    open,
+		Offset:  func(v int) int { return v },
+		Backoff: func(int) time.Duration { return 0 },
+	})
+	vs, err := Collect[int](context.Background(), s)
+	requireNoError(t, err)
+	requireEqual(t, feed, vs)
+	requireEqual(t, 5, s.Offset())
+	requireEqual(t, []int{0, 2, 2, 4}, opens)
+}
+
+func TestResumableGivesUp(t *testing.T) {
+	clock := newFakeClock()
+	fatal := errors.New("forbidden")
+	attempts := 0
+	s := Resumable(0, ResumeConfig[int, int]{
+		Open: func(context.Context, int) (Stream[int], error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("timeout")
+			}
+			return nil, fatal
+		},
+		Offset:    func(v int) int { return v },
+		Retryable: func(err error) bool { return err != fatal },
+		Clock:     clock,
+	})
+	ctx := context.Background()
+	next := NewPromise(func() (int, error) { return s.Next(ctx) })
+	clock.WaitForTimers(1)
+	clock.Advance(100 * time.Millisecond)
+	_, err := next.Await(ctx)
+	requireEqual(t, fatal, err)
+	requireEqual(t, 2, attempts)
+
+	s = Resumable(0, ResumeConfig[int, int]{
+		Open: func(context.Context, int) (Stream[int], error) {
+			return nil, errors.New("timeout")
+		},
+		Offset:      func(v int) int { return v },
+		Backoff:     func(int) time.Duration { return 0 },
+		MaxAttempts: 3,
+	})
+	_, err = s.Next(ctx)
+	requireEqual(t, "timeout", err.Error())
+}
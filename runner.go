@@ -0,0 +1,82 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// RunMode selects how a KeyedRunner treats a task submitted for a key that
+// already has a task in flight.
+type RunMode int
+
+const (
+	// RunMerge runs every task concurrently.
+	RunMerge RunMode = iota
+
+	// RunSwitch cancels the task in flight and runs the new one, so only the
+	// latest submission for a key completes, as for autocompletion.
+	RunSwitch
+
+	// RunExhaust ignores the new task and returns the promise of the task in
+	// flight, as for a sync that must not be started twice.
+	RunExhaust
+
+	// RunConcat queues the new task until every task submitted before it for
+	// the same key has settled, as for saving on every edit.
+	RunConcat
+)
+
+// KeyedRunner runs tasks grouped by key, applying its RunMode to tasks that
+// share a key. Tasks for different keys never affect each other.
+type KeyedRunner[K comparable, T any] struct {
+	mode RunMode
+
+	mu     sync.Mutex
+	latest map[K]CancelablePromise[T]
+}
+
+// NewKeyedRunner creates a KeyedRunner that applies mode.
+func NewKeyedRunner[K comparable, T any](mode RunMode) *KeyedRunner[K, T] {
+	return &KeyedRunner[K, T]{
+		mode:   mode,
+		latest: map[K]CancelablePromise[T]{},
+	}
+}
+
+// Submit runs fn for key according to the runner's mode. fn receives a
+// context derived from ctx, which in RunSwitch mode is canceled when a newer
+// task is submitted for key.
+func (r *KeyedRunner[K, T]) Submit(ctx context.Context, key K, fn func(context.Context) (T, error)) Promise[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.latest[key]
+	if r.mode == RunMerge {
+		return NewCancelablePromise(ctx, fn)
+	}
+	if prev != nil && r.mode == RunExhaust {
+		return prev
+	}
+	if prev != nil && r.mode == RunSwitch {
+		prev.Cancel()
+	}
+
+	var p CancelablePromise[T]
+	p = NewCancelablePromise(ctx, func(ctx context.Context) (T, error) {
+		defer func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.latest[key] == p {
+				delete(r.latest, key)
+			}
+		}()
+		if prev != nil && r.mode == RunConcat {
+			if _, err := prev.Await(ctx); ctx.Err() != nil {
+				var zerov T
+				return zerov, err
+			}
+		}
+		return fn(ctx)
+	})
+	r.latest[key] = p
+	return p
+}
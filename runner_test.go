@@ -0,0 +1,98 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestKeyedRunnerSwitch(t *testing.T) {
+	r := NewKeyedRunner[string, string](RunSwitch)
+	ctx := context.Background()
+	task := func(v string) func(context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			return v, sleepCtx(ctx, time.Millisecond*50)
+		}
+	}
+	first := r.Submit(ctx, "q", task("f"))
+	other := r.Submit(ctx, "other", task("o"))
+	second := r.Submit(ctx, "q", task("fo"))
+
+	_, err := first.Await(ctx)
+	requireEqual(t, context.Canceled, err)
+	v, err := second.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "fo", v)
+	v, err = other.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, "o", v)
+}
+
+func TestKeyedRunnerExhaust(t *testing.T) {
+	r := NewKeyedRunner[string, int](RunExhaust)
+	ctx := context.Background()
+	calls := 0
+	task := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, sleepCtx(ctx, time.Millisecond*50)
+	}
+	first := r.Submit(ctx, "sync", task)
+	second := r.Submit(ctx, "sync", task)
+	requireEqual(t, first, second)
+	v, err := second.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+
+	v, err = r.Submit(ctx, "sync", task).Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 2, v)
+}
+
+func TestKeyedRunnerConcat(t *testing.T) {
+	r := NewKeyedRunner[string, int](RunConcat)
+	ctx := context.Background()
+	var mu sync.Mutex
+	var order []int
+	task := func(i int, d time.Duration) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			time.Sleep(d)
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, i)
+			return i, nil
+		}
+	}
+	promises := []Promise[int]{
+		r.Submit(ctx, "doc", task(1, time.Millisecond*50)),
+		r.Submit(ctx, "doc", task(2, 0)),
+		r.Submit(ctx, "doc", task(3, time.Millisecond*10)),
+	}
+	_, err := All(ctx, promises)
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2, 3}, order)
+}
+
+func TestKeyedRunnerMerge(t *testing.T) {
+	r := NewKeyedRunner[string, int](RunMerge)
+	ctx := context.Background()
+	task := func(ctx context.Context) (int, error) {
+		return 1, sleepCtx(ctx, time.Millisecond*50)
+	}
+	start := time.Now()
+	vs, err := All(ctx, []Promise[int]{r.Submit(ctx, "k", task), r.Submit(ctx, "k", task)})
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 1}, vs)
+	if elapsed := time.Since(start); elapsed > time.Millisecond*90 {
+		t.Fatalf("expected tasks to run concurrently, took %s", elapsed)
+	}
+}
@@ -0,0 +1,52 @@
+package async
+
+import "context"
+
+// Seq returns an iterator over the results of promises, in input order. Each
+// result is yielded as soon as it and every result before it are available,
+// so the results can be processed incrementally while still keeping their
+// order. The iterator has the shape of iter.Seq2[T, error], so it can be used
+// in a range-over-func loop:
+//
+//	for v, err := range async.Seq(ctx, promises) {
+//		...
+//	}
+//
+// A rejected promise is yielded like any other result. If ctx is done, its
+// error is yielded once and the iteration stops. Whenever the iteration stops
+// early, the remaining promises that implement CancelablePromise are
+// canceled.
+func Seq[T any](ctx context.Context, promises []Promise[T]) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		cancel := func(remaining []Promise[T]) {
+			for _, rest := range remaining {
+				if c, ok := rest.(interface{ Cancel() }); ok {
+					c.Cancel()
+				}
+			}
+		}
+		for i, p := range promises {
+			v, err := p.Await(ctx)
+			cerr := ctx.Err()
+			if !yield(v, err) {
+				// when ctx is done, p itself may not have settled
+				if cerr != nil {
+					cancel(promises[i:])
+				} else {
+					cancel(promises[i+1:])
+				}
+				return
+			}
+			if cerr != nil {
+				// p may have settled just as ctx was done, in which case its
+				// result was yielded instead of ctx's error
+				if err != cerr {
+					var zerov T
+					yield(zerov, cerr)
+				}
+				cancel(promises[i:])
+				return
+			}
+		}
+	}
+}
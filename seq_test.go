@@ -0,0 +1,81 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSeq(t *testing.T) {
+	ctx := context.Background()
+	promises := []Promise[int]{
+		NewPromise(func() (int, error) {
+			time.Sleep(time.Millisecond * 50)
+			return 42, nil
+		}),
+		Reject[int](errors.New("doh!")),
+		Resolve(44),
+	}
+	var vs []int
+	var errs int
+	Seq(ctx, promises)(func(v int, err error) bool {
+		if err != nil {
+			errs++
+			return true
+		}
+		vs = append(vs, v)
+		return true
+	})
+	requireEqual(t, []int{42, 44}, vs)
+	requireEqual(t, 1, errs)
+
+	slow := func(ctx context.Context) (int, error) {
+		select {
+		case <-time.After(time.Second):
+			return 0, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	promises = []Promise[int]{
+		Resolve(1),
+		NewCancelablePromise(ctx, slow),
+	}
+	Seq(ctx, promises)(func(v int, err error) bool {
+		return false
+	})
+	_, err := promises[1].Await(ctx)
+	requireEqual(t, context.Canceled, err)
+
+	short, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	pending := NewCancelablePromise(ctx, slow)
+	Seq(short, []Promise[int]{pending})(func(v int, err error) bool {
+		requireEqual(t, context.DeadlineExceeded, err)
+		return true
+	})
+	_, err = pending.Await(ctx)
+	requireEqual(t, context.Canceled, err)
+}
+
+// settledPromise returns its value even when ctx is already done.
+type settledPromise[T any] struct{ v T }
+
+func (p settledPromise[T]) Settled() bool { return true }
+
+func (p settledPromise[T]) Await(context.Context) (T, error) { return p.v, nil }
+
+func TestSeqSettledAsCtxDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var vs []int
+	var errs []error
+	Seq(ctx, []Promise[int]{settledPromise[int]{7}, Resolve(8)})(func(v int, err error) bool {
+		vs = append(vs, v)
+		errs = append(errs, err)
+		return true
+	})
+	requireEqual(t, []int{7, 0}, vs)
+	requireEqual(t, []error{nil, context.Canceled}, errs)
+}
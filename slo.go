@@ -0,0 +1,164 @@
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+// sloSlots is the number of buckets a rolling SLO window is divided into.
+const sloSlots = 60
+
+// SLO describes the service level objective of a named operation.
+type SLO struct {
+	// Target is the fraction of calls that must be good, for instance 0.999.
+	Target float64
+
+	// Latency is the duration above which a call counts as bad even if it
+	// succeeded. Zero means only errors count as bad calls.
+	Latency time.Duration
+
+	// Window is the rolling window over which attainment is computed.
+	Window time.Duration
+
+	// BurnRate is the rate at which the error budget may be consumed before
+	// the tracker's callback is invoked. A burn rate of 1 consumes exactly
+	// the budget over the window.
+	BurnRate float64
+
+	// MinCalls is the number of calls the window must hold before the
+	// callback may be invoked, so that a single early failure does not alert.
+	MinCalls int
+}
+
+// SLOStatus reports how a named operation performs against its SLO over the
+// current window.
+type SLOStatus struct {
+	Name  string
+	Calls int
+	Good  int
+
+	// Attainment is the fraction of good calls, 1 if there were none.
+	Attainment float64
+
+	// BurnRate is the rate at which the error budget is being consumed.
+	BurnRate float64
+}
+
+// SLOTracker records the latency and outcome of named promises against
+// configured SLOs, and invokes a callback when an error budget burns too
+// fast.
+type SLOTracker struct {
+	onBurn func(SLOStatus)
+	now    func() time.Time
+
+	mu  sync.Mutex
+	ops map[string]*sloOp
+}
+
+type sloBucket struct {
+	epoch int64
+	calls int
+	good  int
+}
+
+type sloOp struct {
+	slo      SLO
+	buckets  [sloSlots]sloBucket
+	alerting bool
+}
+
+// NewSLOTracker creates a tracker that calls onBurn whenever the burn rate of
+// an operation rises above its SLO's BurnRate. onBurn is not called again for
+// that operation until its burn rate has dropped back below the threshold.
+func NewSLOTracker(onBurn func(SLOStatus)) *SLOTracker {
+	return &SLOTracker{
+		onBurn: onBurn,
+		now:    time.Now,
+		ops:    map[string]*sloOp{},
+	}
+}
+
+// Configure sets the SLO of the operation called name, discarding what was
+// recorded for it so far.
+func (t *SLOTracker) Configure(name string, slo SLO) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ops[name] = &sloOp{slo: slo}
+}
+
+func (o *sloOp) epoch(now time.Time) int64 {
+	width := o.slo.Window.Nanoseconds() / sloSlots
+	if width <= 0 {
+		width = 1
+	}
+	return now.UnixNano() / width
+}
+
+func (o *sloOp) status(name string, now time.Time) SLOStatus {
+	s := SLOStatus{Name: name, Attainment: 1}
+	cur := o.epoch(now)
+	for _, b := range o.buckets {
+		if b.epoch > cur-sloSlots {
+			s.Calls += b.calls
+			s.Good += b.good
+		}
+	}
+	if s.Calls > 0 {
+		s.Attainment = float64(s.Good) / float64(s.Calls)
+		if budget := 1 - o.slo.Target; budget > 0 {
+			s.BurnRate = (1 - s.Attainment) / budget
+		}
+	}
+	return s
+}
+
+// Record adds a call of the named operation to its window. Calls to
+// operations that were not configured are ignored.
+func (t *SLOTracker) Record(name string, latency time.Duration, err error) {
+	t.mu.Lock()
+	o, ok := t.ops[name]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	now := t.now()
+	cur := o.epoch(now)
+	b := &o.buckets[cur%sloSlots]
+	if b.epoch != cur {
+		*b = sloBucket{epoch: cur}
+	}
+	b.calls++
+	if err == nil && (o.slo.Latency == 0 || latency <= o.slo.Latency) {
+		b.good++
+	}
+	s := o.status(name, now)
+	burning := s.Calls >= o.slo.MinCalls && s.BurnRate > o.slo.BurnRate
+	fire := burning && !o.alerting
+	o.alerting = burning
+	t.mu.Unlock()
+	if fire && t.onBurn != nil {
+		t.onBurn(s)
+	}
+}
+
+// Status reports the current attainment of the named operation.
+func (t *SLOTracker) Status(name string) (SLOStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	o, ok := t.ops[name]
+	if !ok {
+		return SLOStatus{}, false
+	}
+	return o.status(name, t.now()), true
+}
+
+// NewTrackedPromise is like NewPromise, but records the latency and outcome
+// of fn with t under name.
+func NewTrackedPromise[T any](t *SLOTracker, name string, fn func() (T, error)) Promise[T] {
+	return NewPromise(func() (T, error) {
+		start := t.now()
+		v, err := fn()
+		t.Record(name, t.now().Sub(start), err)
+		return v, err
+	})
+}
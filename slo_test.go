@@ -0,0 +1,62 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSLOTracker(t *testing.T) {
+	var alerts []SLOStatus
+	tracker := NewSLOTracker(func(s SLOStatus) {
+		alerts = append(alerts, s)
+	})
+	now := time.Unix(0, 0)
+	tracker.now = func() time.Time { return now }
+	tracker.Configure("fetch", SLO{
+		Target:   0.9,
+		Latency:  time.Second,
+		Window:   time.Minute,
+		BurnRate: 2,
+		MinCalls: 10,
+	})
+
+	for i := 0; i < 8; i++ {
+		tracker.Record("fetch", time.Millisecond, nil)
+	}
+	tracker.Record("fetch", time.Millisecond, errors.New("darn"))
+	tracker.Record("fetch", time.Minute, nil) // too slow
+	s, ok := tracker.Status("fetch")
+	requireEqual(t, true, ok)
+	requireEqual(t, 10, s.Calls)
+	requireEqual(t, 8, s.Good)
+	requireEqual(t, 0.8, s.Attainment)
+	requireEqual(t, 0, len(alerts))
+
+	tracker.Record("fetch", time.Millisecond, errors.New("darn"))
+	tracker.Record("fetch", time.Millisecond, errors.New("darn"))
+	requireEqual(t, 1, len(alerts)) // only once while the budget keeps burning
+
+	now = now.Add(2 * time.Minute)
+	tracker.Record("fetch", time.Millisecond, nil)
+	s, _ = tracker.Status("fetch")
+	requireEqual(t, 1, s.Calls)
+	requireEqual(t, 1.0, s.Attainment)
+
+	_, ok = tracker.Status("unknown")
+	requireEqual(t, false, ok)
+}
+
+func TestNewTrackedPromise(t *testing.T) {
+	tracker := NewSLOTracker(nil)
+	tracker.Configure("fetch", SLO{Target: 0.99, Window: time.Minute})
+	ctx := context.Background()
+	_, err := NewTrackedPromise(tracker, "fetch", func() (int, error) {
+		return 0, errors.New("darn")
+	}).Await(ctx)
+	requireError(t, err)
+	s, _ := tracker.Status("fetch")
+	requireEqual(t, 1, s.Calls)
+	requireEqual(t, 0, s.Good)
+}
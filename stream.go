@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrStreamClosed is returned by Next once the consumer closed the stream.
+var ErrStreamClosed = errors.New("async: stream closed")
+
+// Stream is an abstract representation of a sequence of values that are
+// delivered over time.
+type Stream[T any] interface {
+	// Next blocks until the next value of the stream is available. Once the
+	// stream is exhausted Next returns io.EOF. Any other error means the
+	// stream failed and no more values will be delivered.
+	Next(context.Context) (T, error)
+}
+
+// StreamFunc adapts a function to the Stream interface.
+type StreamFunc[T any] func(context.Context) (T, error)
+
+// Next calls f.
+func (f StreamFunc[T]) Next(ctx context.Context) (T, error) {
+	return f(ctx)
+}
+
+// FromSlice returns a stream that delivers the values of vs in order.
+func FromSlice[T any](vs []T) Stream[T] {
+	i := 0
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		var zerov T
+		if err := ctx.Err(); err != nil {
+			return zerov, err
+		}
+		if i == len(vs) {
+			return zerov, io.EOF
+		}
+		i++
+		return vs[i-1], nil
+	})
+}
+
+// Collect reads s until it is exhausted and returns all of its values. If the
+// stream fails, the values read so far are returned alongside the error.
+func Collect[T any](ctx context.Context, s Stream[T]) ([]T, error) {
+	var out []T
+	for {
+		v, err := s.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, v)
+	}
+}
@@ -0,0 +1,35 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFromSlice(t *testing.T) {
+	ctx := context.Background()
+	s := FromSlice([]int{1, 2, 3})
+	v, err := s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	rest, err := Collect(ctx, s)
+	requireNoError(t, err)
+	requireEqual(t, []int{2, 3}, rest)
+	_, err = s.Next(ctx)
+	requireEqual(t, io.EOF, err)
+}
+
+func TestCollect(t *testing.T) {
+	i := 0
+	s := StreamFunc[int](func(context.Context) (int, error) {
+		i++
+		if i == 3 {
+			return 0, errors.New("doh!")
+		}
+		return i, nil
+	})
+	vs, err := Collect[int](context.Background(), s)
+	requireError(t, err)
+	requireEqual(t, []int{1, 2}, vs)
+}
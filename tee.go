@@ -0,0 +1,164 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrTeeOverflow is returned by a TeeStream using TeeBuffer once its
+// consumer fell more than TeeConfig.Buffer values behind.
+var ErrTeeOverflow = errors.New("async: tee buffer overflow")
+
+// TeeMode selects what Tee does when one of its consumers is slower than the
+// others.
+type TeeMode int
+
+const (
+	// TeeBlock stops reading from the source until every consumer has room
+	// for the next value, so the slowest consumer sets the pace for all.
+	TeeBlock TeeMode = iota
+
+	// TeeBuffer queues up to TeeConfig.Buffer values for each consumer and
+	// fails a consumer with ErrTeeOverflow once it falls further behind.
+	TeeBuffer
+
+	// TeeDrop queues up to TeeConfig.Buffer values for each consumer and
+	// discards values that do not fit.
+	TeeDrop
+)
+
+// TeeConfig configures Tee.
+type TeeConfig struct {
+	Mode TeeMode
+
+	// Buffer is the number of values queued for each consumer.
+	Buffer int
+}
+
+// TeeStream is one of the streams returned by Tee.
+type TeeStream[T any] struct {
+	ch      chan T
+	err     error
+	closed  chan struct{}
+	once    sync.Once
+	onClose func()
+	dropped int64
+}
+
+// Next delivers the next value of the source.
+func (b *TeeStream[T]) Next(ctx context.Context) (T, error) {
+	var zerov T
+	select {
+	case <-ctx.Done():
+		return zerov, ctx.Err()
+	case <-b.closed:
+		return zerov, ErrStreamClosed
+	case v, ok := <-b.ch:
+		if !ok {
+			return zerov, b.err
+		}
+		return v, nil
+	}
+}
+
+// Close detaches the consumer from the source without affecting the other
+// consumers. Once every stream of a Tee is closed, the source is no longer
+// read.
+func (b *TeeStream[T]) Close() {
+	b.once.Do(func() {
+		close(b.closed)
+		b.onClose()
+	})
+}
+
+// Dropped reports how many values were discarded for this consumer in
+// TeeDrop mode.
+func (b *TeeStream[T]) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
+
+func (b *TeeStream[T]) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Tee reads s once and delivers each of its values to n independent streams.
+// The source is read in a separate goroutine until it is exhausted, ctx is
+// done, or every returned stream is closed.
+func Tee[T any](ctx context.Context, s Stream[T], n int, cfg TeeConfig) []*TeeStream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	open := int64(n)
+	onClose := func() {
+		if atomic.AddInt64(&open, -1) == 0 {
+			cancel()
+		}
+	}
+	branches := make([]*TeeStream[T], n)
+	for i := range branches {
+		branches[i] = &TeeStream[T]{
+			ch:      make(chan T, cfg.Buffer),
+			closed:  make(chan struct{}),
+			onClose: onClose,
+		}
+	}
+	if n == 0 {
+		cancel()
+		return branches
+	}
+	go func() {
+		defer cancel()
+		live := append([]*TeeStream[T](nil), branches...)
+		finish := func(b *TeeStream[T], err error) {
+			b.err = err
+			close(b.ch)
+		}
+		for len(live) > 0 {
+			v, err := s.Next(ctx)
+			if err != nil {
+				for _, b := range live {
+					finish(b, err)
+				}
+				return
+			}
+			next := live[:0]
+			for _, b := range live {
+				if b.isClosed() {
+					continue
+				}
+				switch cfg.Mode {
+				case TeeBlock:
+					select {
+					case b.ch <- v:
+					case <-b.closed:
+						continue
+					case <-ctx.Done():
+						finish(b, ctx.Err())
+						continue
+					}
+				case TeeBuffer:
+					select {
+					case b.ch <- v:
+					default:
+						finish(b, ErrTeeOverflow)
+						continue
+					}
+				case TeeDrop:
+					select {
+					case b.ch <- v:
+					default:
+						atomic.AddInt64(&b.dropped, 1)
+					}
+				}
+				next = append(next, b)
+			}
+			live = next
+		}
+	}()
+	return branches
+}
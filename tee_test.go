@@ -0,0 +1,55 @@
+package async
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTee(t *testing.T) {
+	ctx := context.Background()
+	branches := Tee(ctx, FromSlice([]int{1, 2, 3, 4}), 3, TeeConfig{Mode: TeeBlock, Buffer: 1})
+	v, err := branches[2].Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	branches[2].Close() // must not hold back the other consumers
+	_, err = branches[2].Next(ctx)
+	requireEqual(t, ErrStreamClosed, err)
+
+	promises := []Promise[[]int]{
+		NewPromise(func() ([]int, error) { return Collect[int](ctx, branches[0]) }),
+		NewPromise(func() ([]int, error) { return Collect[int](ctx, branches[1]) }),
+	}
+	results, err := All(ctx, promises)
+	requireNoError(t, err)
+	requireEqual(t, [][]int{{1, 2, 3, 4}, {1, 2, 3, 4}}, results)
+}
+
+func TestTeeBuffer(t *testing.T) {
+	ctx := context.Background()
+	source := FromSlice([]int{1, 2, 3, 4})
+	paced := StreamFunc[int](func(ctx context.Context) (int, error) {
+		time.Sleep(time.Millisecond * 10)
+		return source.Next(ctx)
+	})
+	branches := Tee[int](ctx, paced, 2, TeeConfig{Mode: TeeBuffer, Buffer: 2})
+	fast, err := Collect[int](ctx, branches[0])
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2, 3, 4}, fast)
+	slow, err := Collect[int](ctx, branches[1])
+	requireEqual(t, ErrTeeOverflow, err)
+	requireEqual(t, []int{1, 2}, slow)
+}
+
+func TestTeeDrop(t *testing.T) {
+	ctx := context.Background()
+	branches := Tee(ctx, FromSlice([]int{1, 2, 3, 4, 5}), 1, TeeConfig{Mode: TeeDrop, Buffer: 2})
+	time.Sleep(time.Millisecond * 50)
+	vs, err := Collect[int](ctx, branches[0])
+	requireNoError(t, err)
+	requireEqual(t, []int{1, 2}, vs)
+	requireEqual(t, int64(3), branches[0].Dropped())
+	_, err = branches[0].Next(ctx)
+	requireEqual(t, io.EOF, err)
+}
@@ -0,0 +1,285 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrItemTimeout is returned by a stream created with Timeout in TimeoutFail
+// mode when an item takes too long to arrive.
+var ErrItemTimeout = errors.New("async: stream item timed out")
+
+type streamItem[T any] struct {
+	v   T
+	err error
+}
+
+// pumpStream reads s in a separate goroutine until it fails or ctx is done.
+// The returned channel delivers the terminating error as its last item and is
+// closed right after.
+func pumpStream[T any](ctx context.Context, s Stream[T]) <-chan streamItem[T] {
+	out := make(chan streamItem[T])
+	go func() {
+		defer close(out)
+		for {
+			v, err := s.Next(ctx)
+			select {
+			case out <- streamItem[T]{v: v, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// chanStream delivers the items of in. If in is closed without a terminating
+// error, ctx's error is returned instead.
+func chanStream[T any](ctx context.Context, in <-chan streamItem[T]) Stream[T] {
+	var failed error
+	return StreamFunc[T](func(nctx context.Context) (T, error) {
+		var zerov T
+		if failed != nil {
+			return zerov, failed
+		}
+		select {
+		case <-nctx.Done():
+			return zerov, nctx.Err()
+		case it, ok := <-in:
+			switch {
+			case !ok:
+				failed = ctx.Err()
+			case it.err != nil:
+				failed = it.err
+			default:
+				return it.v, nil
+			}
+			return zerov, failed
+		}
+	})
+}
+
+// Throttle delivers at most n items of s per interval. Items are not dropped:
+// once the limit is reached, Next waits until the interval allows another
+// item before reading s. n MUST be positive. A nil clock means SystemClock.
+func Throttle[T any](s Stream[T], n int, interval time.Duration, clock Clock) Stream[T] {
+	if n <= 0 {
+		panic("async: Throttle requires a positive n")
+	}
+	if clock == nil {
+		clock = SystemClock
+	}
+	var sent []time.Time
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		if len(sent) == n {
+			if wait := sent[0].Add(interval).Sub(clock.Now()); wait > 0 {
+				t := clock.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					var zerov T
+					return zerov, ctx.Err()
+				case <-t.C():
+				}
+			}
+			sent = sent[1:]
+		}
+		v, err := s.Next(ctx)
+		if err == nil {
+			sent = append(sent, clock.Now())
+		}
+		return v, err
+	})
+}
+
+// Debounce delivers an item of s only once quiet has passed without a newer
+// item arriving, so that a burst of items is reduced to its last one. The
+// pending item is delivered when s ends. s is read in a separate goroutine
+// until it ends or ctx is done. A nil clock means SystemClock.
+func Debounce[T any](ctx context.Context, s Stream[T], quiet time.Duration, clock Clock) Stream[T] {
+	if clock == nil {
+		clock = SystemClock
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	in := pumpStream(ctx, s)
+	out := make(chan streamItem[T])
+	go func() {
+		defer cancel()
+		defer close(out)
+		var pending *T
+		var t Timer
+		var fire <-chan time.Time
+		send := func(it streamItem[T]) bool {
+			select {
+			case out <- it:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case it, ok := <-in:
+				if !ok {
+					return
+				}
+				if t != nil {
+					t.Stop()
+				}
+				if it.err != nil {
+					if pending != nil && !send(streamItem[T]{v: *pending}) {
+						return
+					}
+					send(it)
+					return
+				}
+				pending = &it.v
+				t = clock.NewTimer(quiet)
+				fire = t.C()
+			case <-fire:
+				v := *pending
+				pending, t, fire = nil, nil, nil
+				if !send(streamItem[T]{v: v}) {
+					return
+				}
+			}
+		}
+	}()
+	return chanStream(ctx, out)
+}
+
+// Sample delivers, once per period, the latest item of s if a new one arrived
+// since the previous sample. The latest unsampled item is delivered when s
+// ends. s is read in a separate goroutine until it ends or ctx is done. A nil
+// clock means SystemClock.
+func Sample[T any](ctx context.Context, s Stream[T], period time.Duration, clock Clock) Stream[T] {
+	if clock == nil {
+		clock = SystemClock
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	in := pumpStream(ctx, s)
+	out := make(chan streamItem[T])
+	go func() {
+		defer cancel()
+		defer close(out)
+		var latest *T
+		t := clock.NewTimer(period)
+		defer func() { t.Stop() }()
+		send := func(it streamItem[T]) bool {
+			select {
+			case out <- it:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case it, ok := <-in:
+				if !ok {
+					return
+				}
+				if it.err != nil {
+					if latest != nil && !send(streamItem[T]{v: *latest}) {
+						return
+					}
+					send(it)
+					return
+				}
+				latest = &it.v
+			case <-t.C():
+				t = clock.NewTimer(period)
+				if latest != nil {
+					v := *latest
+					latest = nil
+					if !send(streamItem[T]{v: v}) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return chanStream(ctx, out)
+}
+
+// TimeoutMode selects what Timeout does with an item that takes too long.
+type TimeoutMode int
+
+const (
+	// TimeoutFail fails the stream with ErrItemTimeout.
+	TimeoutFail TimeoutMode = iota
+
+	// TimeoutSkip discards the late item once it arrives and waits for the
+	// next one.
+	TimeoutSkip
+)
+
+// Timeout limits how long each item of s may take to arrive, measured from
+// the moment the previous item was delivered. s is read in a separate
+// goroutine until it ends or ctx is done. A nil clock means SystemClock.
+func Timeout[T any](ctx context.Context, s Stream[T], d time.Duration, mode TimeoutMode, clock Clock) Stream[T] {
+	if clock == nil {
+		clock = SystemClock
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	in := pumpStream(ctx, s)
+	out := make(chan streamItem[T])
+	go func() {
+		defer cancel()
+		defer close(out)
+		send := func(it streamItem[T]) bool {
+			select {
+			case out <- it:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		// only one read of s is outstanding at a time, so however many times
+		// the timer fires, a single item is late
+		late := false
+		for {
+			t := clock.NewTimer(d)
+			var it streamItem[T]
+		wait:
+			for {
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return
+				case next, ok := <-in:
+					if !ok {
+						t.Stop()
+						return
+					}
+					if next.err == nil && late {
+						late = false
+						continue
+					}
+					t.Stop()
+					it = next
+					break wait
+				case <-t.C():
+					if mode == TimeoutFail {
+						send(streamItem[T]{err: ErrItemTimeout})
+						return
+					}
+					late = true
+					t = clock.NewTimer(d)
+				}
+			}
+			if !send(it) || it.err != nil {
+				return
+			}
+		}
+	}()
+	return chanStream(ctx, out)
+}
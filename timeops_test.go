@@ -0,0 +1,132 @@
+package async
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// gatedStream delivers vs, then signals reached and waits for gate before
+// delivering rest and ending.
+func gatedStream[T any](vs []T, reached chan<- struct{}, gate <-chan struct{}, rest ...T) Stream[T] {
+	first, second := FromSlice(vs), FromSlice(rest)
+	opened := false
+	return StreamFunc[T](func(ctx context.Context) (T, error) {
+		if !opened {
+			v, err := first.Next(ctx)
+			if err != io.EOF {
+				return v, err
+			}
+			close(reached)
+			<-gate
+			opened = true
+		}
+		return second.Next(ctx)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	clock := newFakeClock()
+	ctx := context.Background()
+	s := Throttle(FromSlice([]int{1, 2, 3}), 2, time.Second, clock)
+	for i := 1; i <= 2; i++ {
+		v, err := s.Next(ctx)
+		requireNoError(t, err)
+		requireEqual(t, i, v)
+	}
+	third := NewPromise(func() (int, error) { return s.Next(ctx) })
+	clock.WaitForTimers(1)
+	requireEqual(t, false, third.Settled())
+	clock.Advance(time.Second)
+	v, err := third.Await(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 3, v)
+
+	defer func() {
+		requireEqual(t, "async: Throttle requires a positive n", recover())
+	}()
+	Throttle(FromSlice([]int{1}), 0, time.Second, clock)
+}
+
+func TestDebounce(t *testing.T) {
+	clock := newFakeClock()
+	ctx := context.Background()
+	vs, err := Collect(ctx, Debounce(ctx, FromSlice([]int{1, 2, 3}), time.Second, clock))
+	requireNoError(t, err)
+	requireEqual(t, []int{3}, vs)
+
+	reached, gate := make(chan struct{}), make(chan struct{})
+	s := Debounce(ctx, gatedStream([]int{1}, reached, gate, 2), time.Second, clock)
+	<-reached
+	clock.WaitForTimers(1)
+	clock.Advance(time.Second)
+	v, err := s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	close(gate)
+	vs, err = Collect(ctx, s)
+	requireNoError(t, err)
+	requireEqual(t, []int{2}, vs)
+}
+
+func TestSample(t *testing.T) {
+	clock := newFakeClock()
+	ctx := context.Background()
+	reached, gate := make(chan struct{}), make(chan struct{})
+	s := Sample(ctx, gatedStream([]int{1, 2}, reached, gate), time.Second, clock)
+	<-reached
+	clock.Advance(time.Second)
+	v, err := s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 2, v)
+	close(gate)
+	_, err = s.Next(ctx)
+	requireEqual(t, io.EOF, err)
+}
+
+func TestTimeout(t *testing.T) {
+	clock := newFakeClock()
+	ctx := context.Background()
+	reached, gate := make(chan struct{}), make(chan struct{})
+	s := Timeout(ctx, gatedStream([]int{1}, reached, gate, 2), time.Second, TimeoutFail, clock)
+	v, err := s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	<-reached
+	clock.WaitForTimers(1)
+	clock.Advance(time.Second)
+	_, err = s.Next(ctx)
+	requireEqual(t, ErrItemTimeout, err)
+	close(gate)
+
+	reached, gate = make(chan struct{}), make(chan struct{})
+	s = Timeout(ctx, gatedStream([]int{1}, reached, gate, 2, 3), time.Second, TimeoutSkip, clock)
+	v, err = s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	<-reached
+	clock.WaitForTimers(1)
+	clock.Advance(time.Second)
+	clock.WaitForTimers(1)
+	close(gate)
+	vs, err := Collect(ctx, s)
+	requireNoError(t, err)
+	requireEqual(t, []int{3}, vs)
+
+	reached, gate = make(chan struct{}), make(chan struct{})
+	s = Timeout(ctx, gatedStream([]int{1}, reached, gate, 2, 3, 4), time.Second, TimeoutSkip, clock)
+	v, err = s.Next(ctx)
+	requireNoError(t, err)
+	requireEqual(t, 1, v)
+	<-reached
+	for i := 0; i < 2; i++ {
+		clock.WaitForTimers(1)
+		clock.Advance(time.Second)
+	}
+	clock.WaitForTimers(1)
+	close(gate)
+	vs, err = Collect(ctx, s)
+	requireNoError(t, err)
+	requireEqual(t, []int{3, 4}, vs)
+}
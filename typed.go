@@ -0,0 +1,108 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// PromiseE is a Promise that rejects with a specific error type, so that
+// callers can handle a closed set of errors without errors.As.
+type PromiseE[T any, E error] interface {
+	// Settled indicates if a call to Await will cause a blocking behavior, or
+	// if the result will be immediately returned.
+	Settled() bool
+
+	// Await blocks until the promise settles. The error is the zero E when
+	// the promise resolved.
+	Await(context.Context) (T, E)
+}
+
+type typedPromise[T any, E error] struct {
+	p    Promise[T]
+	wrap func(error) E
+}
+
+func (t *typedPromise[T, E]) Settled() bool {
+	return t.p.Settled()
+}
+
+func (t *typedPromise[T, E]) Await(ctx context.Context) (T, E) {
+	v, err := t.p.Await(ctx)
+	return v, toE(err, t.wrap)
+}
+
+// NewPromiseE is like NewPromise for functions returning a specific error
+// type. Errors that do not come from fn, such as the context's error when it
+// is done before the promise settles, are converted with wrap.
+func NewPromiseE[T any, E error](fn func() (T, E), wrap func(error) E) PromiseE[T, E] {
+	return FromPromise(NewPromise(func() (T, error) {
+		v, e := fn()
+		return v, fromE(e)
+	}), wrap)
+}
+
+// FromPromise adapts p to a PromiseE. Rejections that are not already an E
+// are converted with wrap.
+func FromPromise[T any, E error](p Promise[T], wrap func(error) E) PromiseE[T, E] {
+	return &typedPromise[T, E]{p: p, wrap: wrap}
+}
+
+// ToPromise adapts p to a Promise. A zero E becomes a nil error, so that a
+// nil pointer error type never turns into a non-nil error interface.
+func ToPromise[T any, E error](p PromiseE[T, E]) Promise[T] {
+	if t, ok := p.(*typedPromise[T, E]); ok {
+		return t.p
+	}
+	return untypedPromise[T, E]{p}
+}
+
+type untypedPromise[T any, E error] struct {
+	p PromiseE[T, E]
+}
+
+func (u untypedPromise[T, E]) Settled() bool {
+	return u.p.Settled()
+}
+
+func (u untypedPromise[T, E]) Await(ctx context.Context) (T, error) {
+	v, e := u.p.Await(ctx)
+	return v, fromE(e)
+}
+
+// AllE is All for typed promises. Errors that are not an E, such as the
+// context's error, are converted with wrap.
+func AllE[T any, E error](ctx context.Context, promises []PromiseE[T, E], wrap func(error) E) ([]T, E) {
+	out, err := All(ctx, toPromises(promises))
+	return out, toE(err, wrap)
+}
+
+// RaceE is Race for typed promises. Errors that are not an E, such as the
+// context's error, are converted with wrap.
+func RaceE[T any, E error](ctx context.Context, promises []PromiseE[T, E], wrap func(error) E) (T, E) {
+	v, err := Race(ctx, toPromises(promises))
+	return v, toE(err, wrap)
+}
+
+func toPromises[T any, E error](promises []PromiseE[T, E]) []Promise[T] {
+	out := make([]Promise[T], len(promises))
+	for i, p := range promises {
+		out[i] = ToPromise(p)
+	}
+	return out
+}
+
+func fromE[E error](e E) error {
+	if reflect.ValueOf(&e).Elem().IsZero() {
+		return nil
+	}
+	return e
+}
+
+func toE[E error](err error, wrap func(error) E) E {
+	var e E
+	if err == nil || errors.As(err, &e) {
+		return e
+	}
+	return wrap(err)
+}
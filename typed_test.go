@@ -0,0 +1,82 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type apiError struct {
+	Code int
+	Err  error
+}
+
+func (e *apiError) Error() string {
+	return e.Err.Error()
+}
+
+func wrapAPIError(err error) *apiError {
+	return &apiError{Code: 500, Err: err}
+}
+
+func TestPromiseE(t *testing.T) {
+	ctx := context.Background()
+	promise := NewPromiseE(func() (string, *apiError) {
+		return "foo", nil
+	}, wrapAPIError)
+	v, e := promise.Await(ctx)
+	requireEqual(t, (*apiError)(nil), e)
+	requireEqual(t, "foo", v)
+	_, err := ToPromise(promise).Await(ctx)
+	requireEqual(t, nil, err)
+
+	promise = NewPromiseE(func() (string, *apiError) {
+		return "", &apiError{Code: 404, Err: errors.New("not found")}
+	}, wrapAPIError)
+	_, e = promise.Await(ctx)
+	requireEqual(t, 404, e.Code)
+
+	ctxlowtimeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	promise = NewPromiseE(func() (string, *apiError) {
+		time.Sleep(time.Millisecond * 100)
+		return "too slow", nil
+	}, wrapAPIError)
+	_, e = promise.Await(ctxlowtimeout)
+	requireEqual(t, 500, e.Code)
+	requireEqual(t, context.DeadlineExceeded, e.Err)
+
+	_, e = FromPromise(Reject[string](&apiError{Code: 403, Err: errors.New("forbidden")}), wrapAPIError).Await(ctx)
+	requireEqual(t, 403, e.Code)
+}
+
+func TestAllE(t *testing.T) {
+	ctx := context.Background()
+	promises := []PromiseE[int, *apiError]{
+		FromPromise(Resolve(42), wrapAPIError),
+		FromPromise(Resolve(43), wrapAPIError),
+	}
+	ints, e := AllE(ctx, promises, wrapAPIError)
+	requireEqual(t, (*apiError)(nil), e)
+	requireEqual(t, []int{42, 43}, ints)
+
+	promises = append(promises, FromPromise(Reject[int](&apiError{Code: 409, Err: errors.New("conflict")}), wrapAPIError))
+	_, e = AllE(ctx, promises, wrapAPIError)
+	requireEqual(t, 409, e.Code)
+}
+
+func TestRaceE(t *testing.T) {
+	ctx := context.Background()
+	promises := []PromiseE[int, *apiError]{
+		NewPromiseE(func() (int, *apiError) {
+			time.Sleep(time.Millisecond * 100)
+			return 42, nil
+		}, wrapAPIError),
+		NewPromiseE(func() (int, *apiError) {
+			return 0, &apiError{Code: 429, Err: errors.New("slow down")}
+		}, wrapAPIError),
+	}
+	_, e := RaceE(ctx, promises, wrapAPIError)
+	requireEqual(t, 429, e.Code)
+}
@@ -0,0 +1,260 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// WindowKind selects how WindowAggregate groups items into windows.
+type WindowKind int
+
+const (
+	// TumblingWindow groups items into consecutive, non-overlapping windows
+	// of WindowConfig.Size, aligned on the Unix epoch.
+	TumblingWindow WindowKind = iota
+
+	// SlidingWindow groups items into windows of WindowConfig.Size starting
+	// every WindowConfig.Slide, so that an item may belong to several
+	// windows.
+	SlidingWindow
+
+	// SessionWindow groups items whose timestamps are less than
+	// WindowConfig.Gap apart into the same window.
+	SessionWindow
+)
+
+// WindowConfig configures WindowAggregate.
+type WindowConfig[T any] struct {
+	Kind WindowKind
+
+	// Timestamp returns the event time of an item.
+	Timestamp func(T) time.Time
+
+	// Size is the length of tumbling and sliding windows.
+	Size time.Duration
+
+	// Slide is the interval between the starts of sliding windows.
+	Slide time.Duration
+
+	// Gap is the inactivity that closes a session window.
+	Gap time.Duration
+
+	// MaxOutOfOrder is how far behind the latest event time seen an item may
+	// arrive and still be on time. The watermark, below which windows are
+	// complete, trails the latest event time by this much.
+	MaxOutOfOrder time.Duration
+
+	// AllowedLateness is how long a window is kept once the watermark passed
+	// its end. An item arriving late for a window that is still kept causes
+	// the window to be emitted again as an update; items arriving later are
+	// dropped.
+	AllowedLateness time.Duration
+
+	// OnDropped, if set, is called with every item dropped for being too late.
+	OnDropped func(T)
+}
+
+// WindowResult is the aggregate of the items of one window.
+type WindowResult[A any] struct {
+	Start, End time.Time
+	Value      A
+
+	// Update is set when the window was already emitted and is emitted again
+	// because of late items. It supersedes the previous result.
+	Update bool
+}
+
+type windowState[T any] struct {
+	start, end time.Time
+	items      []T
+	emitted    bool // emitted at least once, so the next result is an update
+	pending    bool // changed since it was last emitted
+}
+
+type windower[T, A any] struct {
+	cfg       WindowConfig[T]
+	aggregate func([]T) A
+
+	windows   []*windowState[T]
+	watermark time.Time
+	started   bool
+	queue     []WindowResult[A]
+}
+
+// WindowAggregate groups the items of s into event time windows and delivers
+// the aggregate of each window once the watermark shows it is complete. Items
+// may arrive out of order, within the bounds set by cfg. When s ends, every
+// window left is emitted. Tumbling and sliding windows MUST have a positive
+// Size.
+func WindowAggregate[T, A any](s Stream[T], cfg WindowConfig[T], aggregate func(items []T) A) Stream[WindowResult[A]] {
+	if cfg.Kind != SessionWindow && cfg.Size <= 0 {
+		panic("async: WindowAggregate requires a positive Size")
+	}
+	if cfg.Kind == TumblingWindow || cfg.Slide <= 0 {
+		cfg.Slide = cfg.Size
+	}
+	w := &windower[T, A]{cfg: cfg, aggregate: aggregate}
+	var failed error
+	return StreamFunc[WindowResult[A]](func(ctx context.Context) (WindowResult[A], error) {
+		for len(w.queue) == 0 {
+			if failed != nil {
+				return WindowResult[A]{}, failed
+			}
+			v, err := s.Next(ctx)
+			switch {
+			case errors.Is(err, io.EOF):
+				w.flush()
+				failed = err
+			case err != nil:
+				failed = err
+			default:
+				w.add(v)
+			}
+		}
+		r := w.queue[0]
+		w.queue = w.queue[1:]
+		return r, nil
+	})
+}
+
+// expired reports whether a window ending at end may no longer change.
+func (w *windower[T, A]) expired(end time.Time) bool {
+	return w.started && !end.Add(w.cfg.AllowedLateness).After(w.watermark)
+}
+
+func (w *windower[T, A]) emit(ws *windowState[T]) {
+	w.queue = append(w.queue, WindowResult[A]{
+		Start:  ws.start,
+		End:    ws.end,
+		Value:  w.aggregate(ws.items),
+		Update: ws.emitted,
+	})
+	ws.emitted, ws.pending = true, false
+}
+
+func (w *windower[T, A]) add(v T) {
+	ts := w.cfg.Timestamp(v)
+	var touched []*windowState[T]
+	if w.cfg.Kind == SessionWindow {
+		if ws := w.addSession(v, ts); ws != nil {
+			touched = append(touched, ws)
+		}
+	} else {
+		touched = w.addFixed(v, ts)
+	}
+	if len(touched) == 0 && w.cfg.OnDropped != nil {
+		w.cfg.OnDropped(v)
+	}
+	for _, ws := range touched {
+		ws.pending = true
+	}
+	if wm := ts.Add(-w.cfg.MaxOutOfOrder); !w.started || wm.After(w.watermark) {
+		w.watermark, w.started = wm, true
+	}
+	w.fire()
+}
+
+// addFixed adds v to every tumbling or sliding window it belongs to.
+func (w *windower[T, A]) addFixed(v T, ts time.Time) []*windowState[T] {
+	slide, size := int64(w.cfg.Slide), int64(w.cfg.Size)
+	n := ts.UnixNano()
+	last := n - n%slide
+	if n%slide < 0 {
+		last -= slide
+	}
+	var touched []*windowState[T]
+	for start := last; start > n-size; start -= slide {
+		s := time.Unix(0, start)
+		e := s.Add(w.cfg.Size)
+		if w.expired(e) {
+			continue
+		}
+		ws := w.find(s)
+		if ws == nil {
+			ws = &windowState[T]{start: s, end: e}
+			w.windows = append(w.windows, ws)
+		}
+		ws.items = append(ws.items, v)
+		touched = append(touched, ws)
+	}
+	return touched
+}
+
+func (w *windower[T, A]) find(start time.Time) *windowState[T] {
+	for _, ws := range w.windows {
+		if ws.start.Equal(start) {
+			return ws
+		}
+	}
+	return nil
+}
+
+// addSession adds v to its session, merging the sessions it bridges.
+func (w *windower[T, A]) addSession(v T, ts time.Time) *windowState[T] {
+	merged := &windowState[T]{start: ts, end: ts.Add(w.cfg.Gap), items: []T{v}}
+	if w.expired(merged.end) {
+		return nil
+	}
+	kept := w.windows[:0]
+	for _, ws := range w.windows {
+		if ws.start.After(merged.end) || merged.start.After(ws.end) {
+			kept = append(kept, ws)
+			continue
+		}
+		if ws.start.Before(merged.start) {
+			merged.start = ws.start
+		}
+		if ws.end.After(merged.end) {
+			merged.end = ws.end
+		}
+		merged.items = append(ws.items, merged.items...)
+		merged.emitted = merged.emitted || ws.emitted
+	}
+	w.windows = append(kept, merged)
+	return merged
+}
+
+// fire emits the windows completed by the watermark, including completed
+// windows that just received late items, and forgets the windows that can no
+// longer be updated.
+func (w *windower[T, A]) fire() {
+	var ready []*windowState[T]
+	kept := w.windows[:0]
+	for _, ws := range w.windows {
+		if ws.pending && !ws.end.After(w.watermark) {
+			ready = append(ready, ws)
+		}
+		if !w.expired(ws.end) {
+			kept = append(kept, ws)
+		}
+	}
+	w.windows = kept
+	w.emitAll(ready)
+}
+
+// flush emits every window with changes that were not emitted yet.
+func (w *windower[T, A]) flush() {
+	var ready []*windowState[T]
+	for _, ws := range w.windows {
+		if ws.pending {
+			ready = append(ready, ws)
+		}
+	}
+	w.windows = nil
+	w.emitAll(ready)
+}
+
+func (w *windower[T, A]) emitAll(ready []*windowState[T]) {
+	sort.Slice(ready, func(i, j int) bool {
+		if !ready[i].end.Equal(ready[j].end) {
+			return ready[i].end.Before(ready[j].end)
+		}
+		return ready[i].start.Before(ready[j].start)
+	})
+	for _, ws := range ready {
+		w.emit(ws)
+	}
+}
@@ -0,0 +1,95 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type windowEvent struct {
+	At    int64
+	Value int
+}
+
+type windowSum struct {
+	Start, End int64
+	Sum        int
+	Update     bool
+}
+
+func collectWindows(t *testing.T, events []windowEvent, cfg WindowConfig[windowEvent]) []windowSum {
+	t.Helper()
+	cfg.Timestamp = func(e windowEvent) time.Time { return time.Unix(e.At, 0) }
+	sum := func(items []windowEvent) int {
+		total := 0
+		for _, e := range items {
+			total += e.Value
+		}
+		return total
+	}
+	results, err := Collect(context.Background(), WindowAggregate(FromSlice(events), cfg, sum))
+	requireNoError(t, err)
+	out := make([]windowSum, len(results))
+	for i, r := range results {
+		out[i] = windowSum{Start: r.Start.Unix(), End: r.End.Unix(), Sum: r.Value, Update: r.Update}
+	}
+	return out
+}
+
+func TestTumblingWindow(t *testing.T) {
+	var dropped []windowEvent
+	results := collectWindows(t, []windowEvent{
+		{1, 1}, {5, 1}, {11, 1}, {13, 1}, {4, 1}, {25, 1}, {3, 1},
+	}, WindowConfig[windowEvent]{
+		Kind:            TumblingWindow,
+		Size:            10 * time.Second,
+		MaxOutOfOrder:   2 * time.Second,
+		AllowedLateness: 5 * time.Second,
+		OnDropped:       func(e windowEvent) { dropped = append(dropped, e) },
+	})
+	requireEqual(t, []windowSum{
+		{Start: 0, End: 10, Sum: 2},
+		{Start: 0, End: 10, Sum: 3, Update: true},
+		{Start: 10, End: 20, Sum: 2},
+		{Start: 20, End: 30, Sum: 1},
+	}, results)
+	requireEqual(t, []windowEvent{{3, 1}}, dropped)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	results := collectWindows(t, []windowEvent{
+		{1, 1}, {7, 1}, {12, 1},
+	}, WindowConfig[windowEvent]{
+		Kind:  SlidingWindow,
+		Size:  10 * time.Second,
+		Slide: 5 * time.Second,
+	})
+	requireEqual(t, []windowSum{
+		{Start: -5, End: 5, Sum: 1},
+		{Start: 0, End: 10, Sum: 2},
+		{Start: 5, End: 15, Sum: 2},
+		{Start: 10, End: 20, Sum: 1},
+	}, results)
+}
+
+func TestSessionWindow(t *testing.T) {
+	results := collectWindows(t, []windowEvent{
+		{1, 1}, {3, 1}, {20, 1}, {4, 1},
+	}, WindowConfig[windowEvent]{
+		Kind:            SessionWindow,
+		Gap:             5 * time.Second,
+		AllowedLateness: 20 * time.Second,
+	})
+	requireEqual(t, []windowSum{
+		{Start: 1, End: 8, Sum: 2},
+		{Start: 1, End: 9, Sum: 3, Update: true},
+		{Start: 20, End: 25, Sum: 1},
+	}, results)
+}
+
+func TestWindowZeroSize(t *testing.T) {
+	defer func() {
+		requireEqual[any](t, "async: WindowAggregate requires a positive Size", recover())
+	}()
+	WindowAggregate(FromSlice([]windowEvent{{1, 1}}), WindowConfig[windowEvent]{Kind: SlidingWindow}, func([]windowEvent) int { return 0 })
+}